@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -24,13 +25,32 @@ type App struct {
 	OnSignal        map[os.Signal]func()
 	OnUnknownSignal func(os.Signal)
 	Conns           map[string]*sql.DB
+	Clusters        map[string]*Cluster
+	HealthInterval  time.Duration
+	SlowQuery       time.Duration // 이 시간 이상 걸린 쿼리는 Warn 로그
+	Migrators       map[string]*Migrator
+	BodyLimit       int64        // 요청 본문 최대 크기 (라우트별 설정 가능, 0 이하면 무제한)
+	SpoolThreshold  int64        // 이 크기를 넘는 본문은 임시파일로 (0이면 사용 안 함)
+	TrustedProxies  []*net.IPNet // 전달 헤더(X-Forwarded-For 등)를 믿을 프록시 대역
 	Router          *Router
+	Templates       *Templates
+	Envelope        Envelope // 응답 봉투 생성기 (기본 DefaultEnvelope)
+	Sessions        *SessionManager
 	Logger          *slog.Logger
 	Handler         *CustomHandler
 }
 
 // 앱 생성자
 func NewApp(WebRoot string) *App {
+	return newApp(NewRouter(WebRoot))
+}
+
+// fs.FS(embed.FS 등)에서 정적 파일을 서빙하는 앱 생성자
+func NewAppFS(fsys fs.FS) *App {
+	return newApp(NewRouterFS(fsys))
+}
+
+func newApp(router *Router) *App {
 	app := &App{
 		Initialize:      func() {},
 		Finalize:        func() {},
@@ -38,14 +58,19 @@ func NewApp(WebRoot string) *App {
 		OnSignal:        make(map[os.Signal]func()),
 		OnUnknownSignal: func(sig os.Signal) {},
 		Conns:           map[string]*sql.DB{},
-		Router:          NewRouter(WebRoot),
+		Clusters:        map[string]*Cluster{},
+		HealthInterval:  10 * time.Second,
+		SlowQuery:       time.Second,
+		Migrators:       map[string]*Migrator{},
+		BodyLimit:       1024 * 1024,
+		Router:          router,
+		Envelope:        DefaultEnvelope,
 	}
 	app.SetLogger(
 		slog.LevelInfo,
 		"",
 		"2006.01.02 15:04:05 (MST)",
 	)
-	app.CreateIndexFiles(WebRoot)
 	app.Server = &http.Server{
 		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			c := NewContext(app, w, r)
@@ -64,6 +89,9 @@ func (a *App) SetLevel(l slog.Level) {
 	a.Handler.level.Set(l)
 	a.Logger.Info(PrependX("LogLevel changed"), "Level", a.Handler.GetLevel())
 }
+
+// 모든 디렉토리에 빈 index.html 생성 (선택)
+// 라우터가 디렉토리 목록을 거부하므로 필수는 아님. 다른 웹서버와 WebRoot 를 공유할 때 사용
 func (a *App) CreateIndexFiles(WebRoot string) {
 	filepath.WalkDir(WebRoot, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -89,6 +117,11 @@ func (a *App) CreateIndexFiles(WebRoot string) {
 
 // 앱 실행
 func (a *App) Run(Addr string, shutdownTimeout time.Duration) {
+	// 실행파일 migrate up|down|status 는 서버 대신 마이그레이션 실행
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(a.Migrate(os.Args[2:]...))
+	}
+
 	a.Server.Addr = Addr
 	a.Initialize()
 
@@ -152,7 +185,13 @@ func (a *App) Shutdown(shutdownTimeout time.Duration) {
 }
 
 func (a *App) RemoveConns() {
+	for _, cl := range a.Clusters {
+		cl.Close(a)
+	}
 	for key, conn := range a.Conns {
+		if _, ok := a.Clusters[key]; ok {
+			continue
+		}
 		if conn != nil {
 			if err := conn.Close(); err != nil {
 				a.Logger.Warn(PrependX("failed to close db connection"), "key", key, "err", err)
@@ -163,15 +202,7 @@ func (a *App) RemoveConns() {
 }
 
 func (a *App) AddConn(key, driver, dsn string) {
-	db, err := sql.Open(driver, dsn)
-	if err != nil {
-		panic(err)
-	}
-	if err := db.Ping(); err != nil {
-		panic(err)
-	}
-	a.Conns[key] = db
-	a.Logger.Info(PrependX("Connection added"), "key", key)
+	a.AddCluster(key, driver, dsn)
 }
 
 // 커넥션 가져오기
@@ -181,10 +212,11 @@ func (a *App) GetConn(key string) *sql.DB {
 
 // AppError 구조체
 type AppError struct {
-	Code string // 에러 코드 (예: "RecordNotFound", "ParameterRequired")
-	Src  string
-	Err  error          // 원본 에러
-	Data map[string]any // 메시지 조립용 데이터
+	Code   string // 에러 코드 (예: "RecordNotFound", "ParameterRequired")
+	Src    string
+	Err    error          // 원본 에러
+	Data   map[string]any // 메시지 조립용 데이터
+	Status int            // HTTP 상태코드 (0이면 200)
 }
 
 // Panic 메서드
@@ -192,6 +224,12 @@ func (e *AppError) Panic() {
 	panic(e)
 }
 
+// HTTP 상태코드 지정
+func (e *AppError) WithStatus(status int) *AppError {
+	e.Status = status
+	return e
+}
+
 // 헬퍼 함수: 에러 생성
 func NewAppError(code string, err error, data map[string]any) *AppError {
 	_, file, line, _ := runtime.Caller(1)
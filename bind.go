@@ -0,0 +1,337 @@
+package x
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 멀티파트 폼 파싱 시 메모리에 둘 최대 크기 (넘으면 임시파일)
+const multipartMemory = 32 << 20
+
+/*
+요청 본문(또는 쿼리스트링)을 구조체로 디코딩한 뒤 validate 태그 검사
+  - application/json                    → ReqBody JSON (스풀된 본문 포함)
+  - application/x-www-form-urlencoded   → PostForm
+  - multipart/form-data                 → MultipartForm 값
+  - 그 외(본문 없음)                    → URL 쿼리스트링
+
+폼/쿼리 필드명은 form 태그, 없으면 json 태그, 없으면 필드명
+실패 시 BindFailed, 검사 실패 시 ValidationFailed 패닉
+*/
+func (c *Context) Bind(dst any) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		NewAppError("BindFailed", fmt.Errorf("Bind needs a pointer to struct, got %T", dst), nil).Panic()
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Req.Header.Get("Content-Type"))
+	var err error
+	switch mediaType {
+	case "application/json":
+		if c.bodyFile != nil {
+			err = json.NewDecoder(c.ReplayBody()).Decode(dst)
+		} else if len(c.ReqBody) > 0 {
+			err = json.Unmarshal(c.ReqBody, dst)
+		}
+	case "application/x-www-form-urlencoded":
+		if err = c.Req.ParseForm(); err == nil {
+			err = bindValues(rv.Elem(), c.Req.PostForm)
+		}
+	case "multipart/form-data":
+		err = bindValues(rv.Elem(), c.multipartForm().Value)
+	default:
+		err = bindValues(rv.Elem(), c.Req.URL.Query())
+	}
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		tooLarge(mbe.Limit).Panic()
+	}
+	if err != nil {
+		NewAppError("BindFailed", err, nil).Panic()
+	}
+
+	if errs := Validate(dst); len(errs) > 0 {
+		NewAppError("ValidationFailed", nil, map[string]any{"Fields": errs}).Panic()
+	}
+}
+
+// 폼/쿼리 필드명
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"form", "json"} {
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func bindValues(v reflect.Value, values url.Values) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("form") == "-" {
+			continue
+		}
+		fv := v.Field(i)
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := bindValues(fv, values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		vals, ok := values[fieldName(f)]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		if err := setField(fv, vals); err != nil {
+			return fmt.Errorf("field %s: %w", fieldName(f), err)
+		}
+	}
+	return nil
+}
+
+func setField(fv reflect.Value, vals []string) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setField(fv.Elem(), vals)
+	}
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setScalar(s.Index(i), val); err != nil {
+				return err
+			}
+		}
+		fv.Set(s)
+		return nil
+	}
+	return setScalar(fv, vals[0])
+}
+
+func setScalar(fv reflect.Value, val string) error {
+	if fv.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Slice:
+		fv.SetBytes([]byte(val))
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+// 검사 실패 항목 (메시지 카탈로그 조립용)
+type FieldError struct {
+	Field string // 요청 필드명 (중첩 구조체는 Parent.Child)
+	Rule  string // required, min, max, len, email, regex
+	Param string // 규칙 인자 (min=3 이면 "3")
+}
+
+func (e FieldError) Error() string {
+	if e.Param == "" {
+		return fmt.Sprintf("%s: %s", e.Field, e.Rule)
+	}
+	return fmt.Sprintf("%s: %s=%s", e.Field, e.Rule, e.Param)
+}
+
+var emailPattern = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+
+var regexCache sync.Map
+
+/*
+validate 태그 검사. 규칙은 쉼표로 구분하고 regex 는 마지막에 둘 것
+
+	Name  string `validate:"required,min=2,max=50"`
+	Email string `validate:"email"`
+	Phone string `validate:"regex=^[0-9-]+$"`
+
+min/max/len 은 숫자면 값, 문자열은 글자 수, 슬라이스/맵은 길이 기준
+빈 문자열/슬라이스와 nil 포인터는 required 외의 규칙을 건너뜀
+숫자는 0 도 검사하므로 (Age int `validate:"min=18"` 에 0 은 실패) 선택 항목은 *int 로
+*/
+func Validate(v any) []FieldError {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	return validateStruct(rv, "")
+}
+
+func validateStruct(v reflect.Value, prefix string) []FieldError {
+	var errs []FieldError
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		inner := reflect.Indirect(fv)
+		nested := inner.Kind() == reflect.Struct && inner.Type() != timeType
+
+		// 임베디드 구조체는 같은 레벨로 검사
+		if f.Anonymous && nested {
+			errs = append(errs, validateStruct(inner, prefix)...)
+			continue
+		}
+
+		name := prefix + fieldName(f)
+		for _, rule := range parseRules(f.Tag.Get("validate")) {
+			if !checkRule(fv, rule[0], rule[1]) {
+				errs = append(errs, FieldError{Field: name, Rule: rule[0], Param: rule[1]})
+			}
+		}
+		if nested {
+			errs = append(errs, validateStruct(inner, name+".")...)
+		}
+	}
+	return errs
+}
+
+// "required,min=2,regex=^a,b$" → [[required ""] [min 2] [regex ^a,b$]]
+func parseRules(tag string) [][2]string {
+	var rules [][2]string
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			rules = append(rules, [2]string{name, param})
+		}
+	}
+	return rules
+}
+
+func checkRule(fv reflect.Value, rule, param string) bool {
+	if rule == "required" {
+		return fv.IsValid() && !fv.IsZero()
+	}
+
+	// 값이 없으면(nil 포인터, 빈 문자열/슬라이스/맵) required 외의 규칙은 통과
+	// 숫자는 0 도 값으로 보고 검사함
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return true
+		}
+		fv = fv.Elem()
+	}
+	if isEmpty(fv) {
+		return true
+	}
+
+	switch rule {
+	case "min", "max", "len":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false
+		}
+		size, ok := measure(fv)
+		if !ok {
+			return false
+		}
+		switch rule {
+		case "min":
+			return size >= limit
+		case "max":
+			return size <= limit
+		default:
+			return size == limit
+		}
+	case "email":
+		return fv.Kind() == reflect.String && emailPattern.MatchString(fv.String())
+	case "regex":
+		re, ok := regexCache.Load(param)
+		if !ok {
+			compiled, err := regexp.Compile(param)
+			if err != nil {
+				return false
+			}
+			re, _ = regexCache.LoadOrStore(param, compiled)
+		}
+		return fv.Kind() == reflect.String && re.(*regexp.Regexp).MatchString(fv.String())
+	}
+	return true
+}
+
+func isEmpty(fv reflect.Value) bool {
+	switch fv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return fv.Len() == 0
+	case reflect.Interface:
+		return fv.IsNil()
+	case reflect.Struct:
+		return fv.IsZero()
+	}
+	return false
+}
+
+// min/max/len 비교 대상 크기
+func measure(fv reflect.Value) (float64, bool) {
+	switch fv.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(fv.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(fv.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), true
+	}
+	return 0, false
+}
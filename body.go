@@ -0,0 +1,124 @@
+package x
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+/*
+요청 본문 버퍼링. 라우트가 정해진 뒤 Router.ServeHTTP 에서 호출
+  - BodyLimit 초과 시 413 PayloadTooLarge
+  - Streaming 라우트는 버퍼링 없이 c.Req.Body 를 그대로 둠 (한도만 적용)
+  - SpoolThreshold 초과 본문은 임시파일로 옮기고 ReqBody 는 nil
+  - multipart 는 버퍼링하지 않음 (FormFile/Bind 에서 직접 파싱)
+*/
+func (c *Context) CopyBody() {
+	if c.Req.Body == nil || c.Req.Body == http.NoBody {
+		c.ReqBody = nil
+		return
+	}
+
+	limit, spool, streaming := c.App.BodyLimit, c.App.SpoolThreshold, false
+	if c.Route != nil {
+		if c.Route.BodyLimit != 0 {
+			limit = c.Route.BodyLimit
+		}
+		if c.Route.SpoolThreshold != 0 {
+			spool = c.Route.SpoolThreshold
+		}
+		streaming = c.Route.Streaming
+	}
+
+	if limit > 0 {
+		if c.Req.ContentLength > limit {
+			tooLarge(limit).Panic()
+		}
+		c.Req.Body = http.MaxBytesReader(c.Res, c.Req.Body, limit)
+	}
+
+	ct := c.Req.Header.Get("Content-Type")
+	if streaming || strings.HasPrefix(ct, "multipart/") {
+		c.ReqBody = nil
+		return
+	}
+
+	// 스풀 임계치까지만 메모리로 읽기
+	src := c.Req.Body
+	head := src
+	if spool > 0 {
+		head = io.NopCloser(io.LimitReader(src, spool+1))
+	}
+	bodyBytes, err := io.ReadAll(head)
+	if err != nil {
+		bodyError(err).Panic()
+	}
+
+	if spool <= 0 || int64(len(bodyBytes)) <= spool {
+		c.ReqBody = bodyBytes
+		c.Req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		return
+	}
+
+	// 임계치 초과분은 임시파일로
+	f, err := os.CreateTemp("", "x-body-*")
+	if err != nil {
+		NewAppError("RuntimeError", err, nil).Panic()
+	}
+	c.bodyFile = f
+	if _, err := f.Write(bodyBytes); err != nil {
+		NewAppError("RuntimeError", err, nil).Panic()
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		bodyError(err).Panic()
+	}
+	c.ReqBody = nil
+	c.ReplayBody()
+}
+
+// 버퍼링(또는 스풀)된 본문을 처음부터 다시 읽을 수 있게 c.Req.Body 를 되감음
+func (c *Context) ReplayBody() io.Reader {
+	switch {
+	case c.bodyFile != nil:
+		if _, err := c.bodyFile.Seek(0, io.SeekStart); err != nil {
+			NewAppError("RuntimeError", err, nil).Panic()
+		}
+		c.Req.Body = io.NopCloser(c.bodyFile)
+	case c.ReqBody != nil:
+		c.Req.Body = io.NopCloser(bytes.NewReader(c.ReqBody))
+	}
+	return c.Req.Body
+}
+
+// 스풀/멀티파트 임시파일 정리 (Recover 에서 호출)
+func (c *Context) removeBodyFile() {
+	if c.Req.MultipartForm != nil {
+		c.Req.MultipartForm.RemoveAll()
+	}
+	if c.bodyFile == nil {
+		return
+	}
+	c.bodyFile.Close()
+	if err := os.Remove(c.bodyFile.Name()); err != nil {
+		c.Warn("failed to remove body spool", "err", err)
+	}
+	c.bodyFile = nil
+}
+
+func tooLarge(limit int64) *AppError {
+	return NewAppError(
+		"PayloadTooLarge", nil, map[string]any{"Limit": limit},
+	).WithStatus(http.StatusRequestEntityTooLarge)
+}
+
+// 본문 읽기 실패: 한도 초과면 413, 그 외 BodyReadFailed
+func bodyError(err error) *AppError {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return tooLarge(mbe.Limit)
+	}
+	return NewAppError("BodyReadFailed", err, nil).WithStatus(http.StatusBadRequest)
+}
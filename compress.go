@@ -0,0 +1,271 @@
+package x
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+/*
+응답 압축 설정 (Router.Use(x.Compress(nil)) 로 라우트/정적 파일 모두 적용)
+  - Accept-Encoding 으로 gzip, deflate 순서로 선택
+  - MinSize 보다 작거나 Types 에 없는 Content-Type 은 그대로 보냄
+  - 이미 Content-Encoding 이 있거나(.br/.gz 사전 압축본) 부분 응답(206)이면 건드리지 않음
+*/
+type Compression struct {
+	Level   int      // 압축 레벨 (gzip.DefaultCompression 등, 사용 전에 지정)
+	MinSize int      // 이 크기(바이트) 미만이면 압축하지 않음
+	Types   []string // 압축할 Content-Type ("text/*" 가능)
+
+	gzipPool sync.Pool
+	zlibPool sync.Pool
+}
+
+func NewCompression() *Compression {
+	return &Compression{
+		Level:   gzip.DefaultCompression,
+		MinSize: 1024,
+		Types: []string{
+			"text/*",
+			"application/json",
+			"application/problem+json",
+			"application/javascript",
+			"application/xml",
+			"image/svg+xml",
+		},
+	}
+}
+
+// 응답 압축 미들웨어. cfg 가 nil 이면 NewCompression 기본값
+func Compress(cfg *Compression) HandlerFunc {
+	if cfg == nil {
+		cfg = NewCompression()
+	}
+	return func(c *Context) {
+		// 웹소켓 등 프로토콜 전환 요청은 제외
+		if c.Req.Header.Get("Upgrade") != "" {
+			return
+		}
+		cw := &compressWriter{cfg: cfg, req: c.Req}
+		c.wrapWriter(func(w http.ResponseWriter) http.ResponseWriter {
+			cw.ResponseWriter = w
+			return cw
+		})
+		c.OnDone(cw.Close)
+	}
+}
+
+func (cfg *Compression) allowed(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return matchMIME(cfg.Types, mt)
+}
+
+// Accept-Encoding 에서 쓸 인코딩 (없으면 "")
+func (cfg *Compression) negotiate(header string) string {
+	for _, enc := range []string{"gzip", "deflate"} {
+		if acceptsEncoding(header, enc) {
+			return enc
+		}
+	}
+	return ""
+}
+
+func (cfg *Compression) encoder(enc string, w io.Writer) io.WriteCloser {
+	switch enc {
+	case "gzip":
+		if gz, ok := cfg.gzipPool.Get().(*gzip.Writer); ok {
+			gz.Reset(w)
+			return gz
+		}
+		gz, err := gzip.NewWriterLevel(w, cfg.Level)
+		if err != nil {
+			gz = gzip.NewWriter(w)
+		}
+		return gz
+	default:
+		// HTTP deflate 는 zlib 포맷 (RFC 9110)
+		if zw, ok := cfg.zlibPool.Get().(*zlib.Writer); ok {
+			zw.Reset(w)
+			return zw
+		}
+		zw, err := zlib.NewWriterLevel(w, cfg.Level)
+		if err != nil {
+			zw = zlib.NewWriter(w)
+		}
+		return zw
+	}
+}
+
+func (cfg *Compression) release(w io.WriteCloser) {
+	switch e := w.(type) {
+	case *gzip.Writer:
+		cfg.gzipPool.Put(e)
+	case *zlib.Writer:
+		cfg.zlibPool.Put(e)
+	}
+}
+
+/*
+압축 여부는 헤더를 보내기 전에 정해야 하므로
+MinSize 만큼 모이거나 Flush/Close 될 때까지 본문과 상태코드를 잡아둠
+*/
+type compressWriter struct {
+	http.ResponseWriter
+	cfg     *Compression
+	req     *http.Request
+	status  int
+	buf     []byte
+	enc     io.WriteCloser // 압축 중이면 인코더
+	decided bool
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	if status >= 100 && status < 200 {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+	// 본문이 없는 응답은 기다릴 필요 없음
+	if status == http.StatusNoContent || status == http.StatusNotModified || w.req.Method == http.MethodHead {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.cfg.MinSize {
+			return len(b), nil
+		}
+		return len(b), w.decide(false)
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// 압축 여부를 정하고 헤더와 잡아둔 본문을 내보냄
+func (w *compressWriter) decide(streaming bool) error {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if enc := w.encoding(streaming); enc != "" {
+		h := w.Header()
+		h.Set("Content-Encoding", enc)
+		h.Del("Content-Length")
+		// 압축본은 바이트가 달라지므로 약한 검증자로
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		w.enc = w.cfg.encoder(enc, w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// 압축할 인코딩. 스트리밍(Flush)이면 크기 조건은 보지 않음
+func (w *compressWriter) encoding(streaming bool) string {
+	if w.status < http.StatusOK || w.status == http.StatusNoContent ||
+		w.status == http.StatusNotModified || w.status == http.StatusPartialContent {
+		return ""
+	}
+	if w.req.Method == http.MethodHead {
+		return ""
+	}
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return ""
+	}
+
+	ct := h.Get("Content-Type")
+	if ct == "" && len(w.buf) > 0 {
+		// net/http 과 같은 방식으로 판별해 둠 (압축 후에는 판별 불가)
+		ct = http.DetectContentType(w.buf)
+		h.Set("Content-Type", ct)
+	}
+	if !w.cfg.allowed(ct) {
+		return ""
+	}
+	// 압축 가능한 응답은 클라이언트와 관계없이 캐시가 구분하도록
+	if !hasVary(h, "Accept-Encoding") {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	if !streaming && len(w.buf) < w.cfg.MinSize {
+		return ""
+	}
+	return w.cfg.negotiate(w.req.Header.Get("Accept-Encoding"))
+}
+
+func hasVary(h http.Header, name string) bool {
+	for _, v := range h.Values("Vary") {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f == "*" || strings.EqualFold(f, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+	if fl, ok := w.enc.(interface{ Flush() error }); ok {
+		fl.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// 응답 종료 시 (c.OnDone) 남은 본문을 내보내고 인코더 정리
+func (w *compressWriter) Close() {
+	if !w.decided && (w.status != 0 || len(w.buf) > 0) {
+		w.decide(false)
+	}
+	if w.enc != nil {
+		w.enc.Close()
+		w.cfg.release(w.enc)
+		w.enc = nil
+	}
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		w.decided = true
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("hijack not supported")
+}
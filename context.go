@@ -2,13 +2,15 @@ package x
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net"
+	"html/template"
 	"net/http"
+	"os"
+	"reflect"
 	"runtime/debug"
-	"strings"
 	"time"
 
 	"github.com/simjinhyun/x/util"
@@ -16,18 +18,31 @@ import (
 
 // Context : 요청/응답을 담는 컨텍스트
 type Context struct {
-	App       *App
-	Req       *http.Request
-	Res       http.ResponseWriter
-	AppError  *AppError
-	RouteType string
-	Store     map[string]any //핸들러 체인들이 자유롭게 데이터 담을 수 있게
-	ReqID     string
-	ReqTime   time.Time
-	ReqBody   []byte
-	RemoteIP  string
-	Route     *Route
-	Response  struct {
+	App           *App
+	Req           *http.Request
+	Res           http.ResponseWriter
+	Ctx           context.Context // 요청 컨텍스트 (클라이언트 종료/라우트 타임아웃 시 취소)
+	cancel        context.CancelFunc
+	AppError      *AppError
+	RouteType     string
+	Store         map[string]any //핸들러 체인들이 자유롭게 데이터 담을 수 있게
+	values        map[any]any    // Key[T] 로 담은 값
+	ReqID         string
+	ReqTime       time.Time
+	ReqBody       []byte
+	bodyFile      *os.File // 임계치를 넘어 임시파일로 옮긴 본문
+	uploadChecked bool
+	RemoteIP      string
+	Route         *Route
+	Template      string // ReplyHTML 로 렌더할 템플릿 이름 (c.Render)
+	// true 이면 쓰기 이후 같은 요청의 읽기는 primary로 보냄
+	StickyWrites bool
+	wrote        map[string]bool
+	onDone       []func()
+	onBody       []func()
+	session      *Session // c.Session() 을 호출했을 때만 로드
+	aborted      bool
+	Response     struct {
 		Code    string
 		Message string
 		Data    any
@@ -40,79 +55,34 @@ func NewContext(a *App, w http.ResponseWriter, r *http.Request) *Context {
 	c := &Context{
 		App:      a,
 		Req:      r,
-		Res:      w,
+		Res:      &responseWriter{ResponseWriter: w},
+		Ctx:      r.Context(),
+		cancel:   func() {},
 		Store:    map[string]any{},
+		wrote:    map[string]bool{},
 		ReqID:    util.EncodeToBase62(uint64(now.UnixNano())),
 		ReqTime:  now,
-		RemoteIP: getClientIP(r),
+		RemoteIP: getClientIP(r, a.TrustedProxies),
 	}
-	c.CopyBody()
 	return c
 }
 
-func (c *Context) CopyBody() {
-	if c.Req.Body == nil {
-		c.ReqBody = nil
-		return
-	}
-
-	ct := c.Req.Header.Get("Content-Type")
-	if strings.HasPrefix(ct, "multipart/") {
-		c.ReqBody = nil
-		return
-	}
-
-	// 최대 1MB까지만 읽기
-	bodyBytes, err := io.ReadAll(io.LimitReader(c.Req.Body, 1024*1024))
-	if err != nil {
-		c.ReqBody = nil
-		return
-	}
-
-	// Body 복원
-	c.Req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-	c.ReqBody = bodyBytes
-}
-
-func getClientIP(r *http.Request) string {
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			ipStr := strings.TrimSpace(ips[0])
-			if net.ParseIP(ipStr) != nil {
-				return ipStr
-			}
-		}
-	}
-
-	if xRealIP := r.Header.Get("X-Real-IP"); xRealIP != "" {
-		if net.ParseIP(xRealIP) != nil {
-			return xRealIP
-		}
-	}
-
-	if cfConnectingIP := r.Header.Get("CF-Connecting-IP"); cfConnectingIP != "" {
-		if net.ParseIP(cfConnectingIP) != nil {
-			return cfConnectingIP
-		}
-	}
-
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-	return ip
-}
-
 var noErr = NewAppError("OK", nil, nil)
 
 func (c *Context) Recover() {
+	defer c.cancel()
+	defer c.removeBodyFile()
+
 	if rec := recover(); rec != nil {
 		var appErr *AppError
 		switch e := rec.(type) {
 		case *AppError:
 			appErr = e
 		case error:
+			if errors.Is(e, context.DeadlineExceeded) {
+				appErr = timeoutError(e, nil)
+				break
+			}
 			appErr = NewAppError("RuntimeError", e, nil)
 			c.App.Logger.Error(fmt.Sprintf("%s", debug.Stack()))
 		default:
@@ -128,8 +98,21 @@ func (c *Context) Recover() {
 	c.Response.Elapsed = time.Since(c.ReqTime).String()
 
 	//정적파일 서빙은 ServeFile 함수가 직접 응답함.
-	if c.Route != nil {
+	//핸들러가 c.JSON 등으로 이미 응답했으면 Reply 생략
+	switch {
+	case c.Written():
+	case c.Route != nil:
 		c.Route.Reply(c)
+	case c.AppError != noErr:
+		// 라우트 없는 경로(정적 파일)에서 미들웨어가 에러로 중단한 경우
+		ReplyJSON(c)
+	}
+	// 세션은 보통 첫 쓰기 직전에 저장됨. 아무것도 쓰지 않았거나 쓴 뒤에 바뀐 경우
+	if c.session != nil {
+		c.session.persist()
+	}
+	for i := len(c.onDone) - 1; i >= 0; i-- {
+		c.onDone[i]()
 	}
 
 	//디버그 로그 (운영 성능 영향 제로)
@@ -142,33 +125,103 @@ func (c *Context) Recover() {
 	)
 }
 
+// 남은 미들웨어/핸들러와 정적 파일 서빙을 건너뜀 (응답은 호출한 쪽이 씀)
+func (c *Context) Abort() {
+	c.aborted = true
+}
+
+func (c *Context) Aborted() bool {
+	return c.aborted
+}
+
+// 라우트가 정해지고 본문을 버퍼링한 뒤(핸들러 직전) 실행할 함수
+func (c *Context) OnBody(fn func()) {
+	c.onBody = append(c.onBody, fn)
+}
+
+// 응답을 다 쓴 뒤 Recover 에서 실행할 함수 (등록 역순)
+func (c *Context) OnDone(fn func()) {
+	c.onDone = append(c.onDone, fn)
+}
+
+// 응답 기록(responseWriter) 아래에 ResponseWriter 를 끼워 넣음 (압축 등)
+func (c *Context) wrapWriter(wrap func(http.ResponseWriter) http.ResponseWriter) {
+	if w, ok := c.Res.(*responseWriter); ok {
+		w.ResponseWriter = wrap(w.ResponseWriter)
+		return
+	}
+	c.Res = wrap(c.Res)
+}
+
+// 요청 시간 초과 (라우트 타임아웃, 취소된 쿼리). 성공으로 보이지 않게 503
+func timeoutError(err error, data map[string]any) *AppError {
+	return NewAppError("Timeout", err, data).WithStatus(http.StatusServiceUnavailable)
+}
+
+// 응답 상태코드: AppError 에 지정된 값, 없으면 200
+func (c *Context) status() int {
+	if c.AppError != nil && c.AppError.Status != 0 {
+		return c.AppError.Status
+	}
+	return http.StatusOK
+}
+
 func ReplyJSON(c *Context) {
-	c.Res.Header().Set("Content-Type", "application/json; charset=utf-8")
+	status, contentType, body := c.envelope()
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Res.Header().Set("Content-Type", contentType)
+	c.Res.WriteHeader(status)
 
-	if err := json.NewEncoder(c.Res).Encode(c.Response); err != nil {
+	if err := json.NewEncoder(c.Res).Encode(body); err != nil {
 		http.Error(c.Res, err.Error(), http.StatusInternalServerError)
 	}
 }
 
 func ReplyHTML(c *Context) {
-	c.Res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	t := c.App.Templates
+	if c.Response.Code == "OK" && c.Template != "" && t != nil {
+		c.renderHTML(c.Template, c.Response.Data)
+		return
+	}
+	if c.Response.Code != "OK" && t != nil && t.ErrorPage != "" {
+		c.renderHTML(t.ErrorPage, c.Response)
+		return
+	}
 
+	html, ok := c.Response.Data.(string)
+	if c.Response.Code == "OK" && !ok {
+		//응답데이터가 html 텍스트가 아니므로 JSON 마샬 응답
+		ReplyJSON(c)
+		return
+	}
+
+	c.Res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.Res.WriteHeader(c.status())
 	if c.Response.Code == "OK" {
-		if html, ok := c.Response.Data.(string); ok {
-			fmt.Fprint(c.Res, html)
-		} else {
-			//응답데이터가 html 텍스트가 아니므로 JSON 마샬 응답
-			ReplyJSON(c)
-		}
+		fmt.Fprint(c.Res, html)
 	} else {
 		fmt.Fprintf(
 			c.Res,
 			"<html><body><h1>Error: %s</h1></body></html>",
-			c.Response.Code,
+			template.HTMLEscapeString(c.Response.Code),
 		)
 	}
 }
 
+func (c *Context) renderHTML(name string, data any) {
+	var buf bytes.Buffer
+	if err := c.App.Templates.render(&buf, name, data, c); err != nil {
+		c.Error("template render failed", "Template", name, "err", err)
+		http.Error(c.Res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	c.Res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.Res.WriteHeader(c.status())
+	buf.WriteTo(c.Res)
+}
+
 func (c *Context) Debug(msg string, args ...interface{}) {
 	c.App.Logger.Debug(c.PrependReqID(msg), args...)
 }
@@ -207,6 +260,34 @@ func (c *Context) Get(key string) any {
 	return c.Store[key]
 }
 
+func (c *Context) GetString(key string) string {
+	if v, ok := c.Store[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func (c *Context) GetBool(key string) bool {
+	if v, ok := c.Store[key].(bool); ok {
+		return v
+	}
+	return false
+}
+
+func (c *Context) GetInt(key string) int {
+	if v, ok := c.Store[key].(int); ok {
+		return v
+	}
+	return 0
+}
+
+func (c *Context) GetTime(key string) time.Time {
+	if v, ok := c.Store[key].(time.Time); ok {
+		return v
+	}
+	return time.Time{}
+}
+
 func (c *Context) GetFloat64(key string) float64 {
 	if v, ok := c.Store[key].(float64); ok {
 		return v
@@ -262,3 +343,60 @@ func (c *Context) GetError(key string) error {
 	}
 	return nil
 }
+
+// 타입 지정 Get: 값이 없거나 타입이 다르면 ok=false
+func Get[T any](c *Context, key string) (T, bool) {
+	v, ok := c.Store[key].(T)
+	return v, ok
+}
+
+// 값이 없거나 타입이 다르면 StoreValueMissing 패닉
+func MustGet[T any](c *Context, key string) T {
+	v, ok := Get[T](c, key)
+	if !ok {
+		// %T 는 인터페이스 타입의 zero 값에서 <nil> 이 되므로 타입 자체로
+		err := fmt.Errorf("store key %q: want %s", key, reflect.TypeFor[T]())
+		NewAppError("StoreValueMissing", err, map[string]any{"Key": key}).Panic()
+	}
+	return v
+}
+
+/*
+미들웨어 간 문자열 키 충돌을 막는 타입 지정 키
+키 값 자체(포인터)로 구분하므로 이름이 같아도 충돌하지 않음
+
+	var UserKey = x.NewKey[*User]("User")
+	UserKey.Set(c, u)
+	u := UserKey.MustGet(c)
+*/
+type Key[T any] struct {
+	name string
+}
+
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+func (k *Key[T]) String() string {
+	return k.name
+}
+
+func (k *Key[T]) Set(c *Context, v T) {
+	if c.values == nil {
+		c.values = map[any]any{}
+	}
+	c.values[k] = v
+}
+
+func (k *Key[T]) Get(c *Context) (T, bool) {
+	v, ok := c.values[k].(T)
+	return v, ok
+}
+
+func (k *Key[T]) MustGet(c *Context) T {
+	v, ok := k.Get(c)
+	if !ok {
+		NewAppError("StoreValueMissing", fmt.Errorf("store key %q not set", k.name), map[string]any{"Key": k.name}).Panic()
+	}
+	return v
+}
@@ -0,0 +1,163 @@
+package x
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+CORS 정책. 전역은 Router.Use(x.CORS(p)), 일부 API 만이면 Group/Route 의 WithCORS
+  - AllowOrigins: "https://app.example.com", "https://*.example.com", "*"
+  - preflight(OPTIONS + Access-Control-Request-Method)는 라우터가 자동 응답
+*/
+type CORSPolicy struct {
+	AllowOrigins     []string
+	AllowMethods     []string // 비어있으면 해당 경로에 등록된 메서드
+	AllowHeaders     []string // 비어있으면 요청한 헤더(Access-Control-Request-Headers)를 그대로 허용
+	ExposeHeaders    []string // 스크립트에서 읽을 수 있게 할 응답 헤더
+	AllowCredentials bool     // 쿠키/인증 헤더 포함 요청 허용 (Origin 을 그대로 돌려줌, "*" 와 함께 쓰면 패닉)
+	MaxAge           time.Duration
+}
+
+func NewCORSPolicy(origins ...string) *CORSPolicy {
+	return &CORSPolicy{
+		AllowOrigins: origins,
+		MaxAge:       10 * time.Minute,
+	}
+}
+
+// 전역 CORS 미들웨어. preflight 는 여기서 응답하고 이후 처리를 중단
+func CORS(p *CORSPolicy) HandlerFunc {
+	p.validate()
+	return func(c *Context) {
+		if isPreflight(c.Req) {
+			methods := c.App.Router.methods(c.Req.URL.Path)
+			if len(methods) == 0 {
+				// 정적 파일 경로
+				methods = []string{http.MethodGet, http.MethodHead}
+			}
+			p.preflight(c, methods)
+			c.Abort()
+			return
+		}
+		p.apply(c)
+	}
+}
+
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions &&
+		req.Header.Get("Origin") != "" &&
+		req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// 와일드카드는 서브도메인 자리에만 (https://*.example.com)
+func (p *CORSPolicy) allowOrigin(origin string) bool {
+	for _, o := range p.AllowOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+		prefix, suffix, ok := strings.Cut(strings.ToLower(o), "*")
+		if !ok {
+			continue
+		}
+		origin := strings.ToLower(origin)
+		if len(origin) <= len(prefix)+len(suffix) ||
+			!strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			continue
+		}
+		if isHostLabels(origin[len(prefix) : len(origin)-len(suffix)]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHostLabels(s string) bool {
+	for _, ch := range s {
+		if !(ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '-' || ch == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// 모든 Origin 에 credentials 허용은 아무 사이트나 인증된 요청을 보낼 수 있게 되므로 금지 (Fetch 표준)
+func (p *CORSPolicy) validate() {
+	if p != nil && p.AllowCredentials && p.anyOrigin() {
+		panic(errors.New(`CORS: AllowOrigins "*" cannot be combined with AllowCredentials`))
+	}
+}
+
+func (p *CORSPolicy) anyOrigin() bool {
+	for _, o := range p.AllowOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// 허용된 Origin 이면 Access-Control-Allow-Origin 등 설정
+func (p *CORSPolicy) allow(h http.Header, origin string) bool {
+	if p.anyOrigin() {
+		// "*" 에는 credentials 를 붙이지 않음 (등록 시 패닉, 이후 필드를 바꿔도 안전하게)
+		h.Set("Access-Control-Allow-Origin", "*")
+		return true
+	}
+	// Origin 마다 응답이 달라지므로 캐시가 구분하도록
+	h.Add("Vary", "Origin")
+	if origin == "" || !p.allowOrigin(origin) {
+		return false
+	}
+	h.Set("Access-Control-Allow-Origin", origin)
+	if p.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	return true
+}
+
+// 실제 요청 응답 헤더
+func (p *CORSPolicy) apply(c *Context) {
+	origin := c.Req.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+	h := c.Res.Header()
+	if p.allow(h, origin) && len(p.ExposeHeaders) > 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(p.ExposeHeaders, ", "))
+	}
+}
+
+// preflight 응답. 허용되지 않은 Origin 은 CORS 헤더 없이 204 (브라우저가 차단)
+func (p *CORSPolicy) preflight(c *Context, methods []string) {
+	h := c.Res.Header()
+	h.Add("Vary", "Access-Control-Request-Method, Access-Control-Request-Headers")
+	if p.allow(h, c.Req.Header.Get("Origin")) {
+		if len(p.AllowMethods) > 0 {
+			methods = p.AllowMethods
+		}
+		h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+		if len(p.AllowHeaders) > 0 {
+			h.Set("Access-Control-Allow-Headers", strings.Join(p.AllowHeaders, ", "))
+		} else if req := c.Req.Header.Get("Access-Control-Request-Headers"); req != "" {
+			h.Set("Access-Control-Allow-Headers", req)
+		}
+		if p.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+		}
+	} else {
+		c.Debug("CORS origin rejected", "Origin", c.Req.Header.Get("Origin"))
+	}
+	c.Res.WriteHeader(http.StatusNoContent)
+}
+
+// 라우트 CORS 정책 설정
+func (rt *Route) WithCORS(p *CORSPolicy) *Route {
+	p.validate()
+	rt.CORS = p
+	return rt
+}
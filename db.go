@@ -0,0 +1,438 @@
+package x
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 하나의 논리 이름 아래 묶인 커넥션 (primary 1개 + replica N개)
+type Cluster struct {
+	Key      string
+	Primary  *Node
+	Replicas []*Node
+	next     atomic.Uint64
+	done     chan struct{}
+}
+
+// 개별 DB 커넥션과 헬스 상태
+type Node struct {
+	DB      *sql.DB
+	healthy atomic.Bool
+}
+
+func (n *Node) Healthy() bool {
+	return n.healthy.Load()
+}
+
+// 쓰기용 커넥션
+func (cl *Cluster) Writer() *sql.DB {
+	return cl.Primary.DB
+}
+
+// 읽기용 커넥션: 건강한 replica를 라운드로빈, 없으면 primary
+func (cl *Cluster) Reader() *sql.DB {
+	n := len(cl.Replicas)
+	if n == 0 {
+		return cl.Primary.DB
+	}
+	start := cl.next.Add(1)
+	for i := 0; i < n; i++ {
+		node := cl.Replicas[(start+uint64(i))%uint64(n)]
+		if node.Healthy() {
+			return node.DB
+		}
+	}
+	return cl.Primary.DB
+}
+
+// 주기적으로 ping 해서 헬스 상태 갱신
+func (cl *Cluster) watch(a *App, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cl.done:
+			return
+		case <-ticker.C:
+			cl.check(a, "primary", cl.Primary)
+			for i, node := range cl.Replicas {
+				cl.check(a, fmt.Sprintf("replica%d", i), node)
+			}
+		}
+	}
+}
+
+func (cl *Cluster) check(a *App, name string, node *Node) {
+	// 응답 없는 호스트에 막혀 다음 체크가 밀리지 않게
+	ctx, cancel := context.WithTimeout(context.Background(), a.HealthInterval)
+	defer cancel()
+	ok := node.DB.PingContext(ctx) == nil
+	if node.healthy.Swap(ok) != ok {
+		a.Logger.Warn(PrependX("Connection health changed"), "key", cl.Key, "node", name, "healthy", ok)
+	}
+}
+
+func (cl *Cluster) Close(a *App) {
+	close(cl.done)
+	for i, node := range cl.Replicas {
+		if err := node.DB.Close(); err != nil {
+			a.Logger.Warn(PrependX("failed to close db connection"), "key", cl.Key, "replica", i, "err", err)
+		}
+	}
+	if err := cl.Primary.DB.Close(); err != nil {
+		a.Logger.Warn(PrependX("failed to close db connection"), "key", cl.Key, "err", err)
+	}
+	a.Logger.Info(PrependX("Connection removed"), "key", cl.Key)
+}
+
+// 커넥션 등록 옵션
+type ConnOptions struct {
+	Retries    int           // 실패 후 재시도 횟수 (음수면 무한)
+	Backoff    time.Duration // 첫 재시도 대기 시간 (이후 2배씩 증가)
+	MaxBackoff time.Duration // 재시도 대기 시간 상한
+	Timeout    time.Duration // 전체 연결 시도 시간 한도 (0이면 무제한)
+	// true 이면 기다리지 않고 백그라운드에서 연결.
+	// Retries 가 0 이면 한 번만 시도하고, 이후는 헬스 체크(HealthInterval)가 살아난 노드를 반영
+	Lazy bool
+}
+
+// 등록할 때 한 번 만들어 모든 노드가 공유 (노드 수와 관계없이 Timeout 이 전체 한도)
+func (opt ConnOptions) context() (context.Context, context.CancelFunc) {
+	if opt.Timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), opt.Timeout)
+}
+
+// 연결될 때까지 backoff 하며 ping (ctx 가 끝나면 진행 중인 ping 도 중단)
+func (opt ConnOptions) ping(ctx context.Context, db *sql.DB) error {
+	backoff := opt.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	maxBackoff := opt.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() == nil && (opt.Retries < 0 || attempt < opt.Retries) {
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff):
+				backoff = min(backoff*2, maxBackoff)
+				continue
+			}
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("connect timeout after %s: %w", opt.Timeout, err)
+		}
+		return err
+	}
+}
+
+// primary + replica 등록. 읽기는 replica, 쓰기는 primary로 라우팅
+func (a *App) AddCluster(key, driver, primary string, replicas ...string) {
+	if err := a.AddClusterWithOptions(ConnOptions{}, key, driver, primary, replicas...); err != nil {
+		panic(err)
+	}
+}
+
+// 패닉 대신 에러를 돌려주는 등록. Lazy 이면 연결 전까지 unhealthy 상태로 등록됨
+func (a *App) AddClusterWithOptions(opt ConnOptions, key, driver, primary string, replicas ...string) error {
+	// 덮어쓰면 기존 커넥션 풀과 헬스 체크가 닫히지 않고 남음
+	if _, ok := a.Conns[key]; ok {
+		return fmt.Errorf("connection %q already registered", key)
+	}
+	cl := &Cluster{
+		Key:  key,
+		done: make(chan struct{}),
+	}
+
+	var nodes []*Node
+	registered := false
+	ctx, cancel := opt.context()
+	// 중간에 실패하면 이미 연 커넥션 정리
+	defer func() {
+		if !registered {
+			cancel()
+			for _, n := range nodes {
+				n.DB.Close()
+			}
+		}
+	}()
+
+	for _, ref := range append([]string{primary}, replicas...) {
+		// DSN은 env:/file:/enc: 참조 가능 (ResolveSecret)
+		dsn, err := ResolveSecret(ref)
+		if err != nil {
+			return fmt.Errorf("connection %q: %w", key, err)
+		}
+		db, err := sql.Open(driver, dsn)
+		if err != nil {
+			return err
+		}
+		node := &Node{DB: db}
+		nodes = append(nodes, node)
+
+		if opt.Lazy {
+			continue
+		}
+		if err := opt.ping(ctx, db); err != nil {
+			return err
+		}
+		node.healthy.Store(true)
+	}
+	cl.Primary, cl.Replicas = nodes[0], nodes[1:]
+	registered = true
+
+	a.Clusters[key] = cl
+	a.Conns[key] = cl.Primary.DB
+	// 헬스 체크는 replica 가 있거나(읽기 분배) Lazy 일 때만 (아직 연결 안 된 노드를 살림)
+	if len(cl.Replicas) > 0 || opt.Lazy {
+		go cl.watch(a, a.HealthInterval)
+	}
+
+	if !opt.Lazy {
+		cancel()
+		a.Logger.Info(PrependX("Connection added"), "key", key, "replicas", len(cl.Replicas))
+		return nil
+	}
+
+	// 연결 전에 Close 되면 진행 중인 ping 도 중단
+	go func() {
+		select {
+		case <-cl.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Go(func() {
+			if err := opt.ping(ctx, node.DB); err != nil {
+				a.Logger.Error(PrependX("Connection failed"), "key", key, "node", i, "err", err)
+				return
+			}
+			node.healthy.Store(true)
+			a.Logger.Info(PrependX("Connection established"), "key", key, "node", i)
+		})
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+	a.Logger.Info(PrependX("Connection added (lazy)"), "key", key, "replicas", len(cl.Replicas))
+	return nil
+}
+
+func (a *App) AddConnWithOptions(key, driver, dsn string, opt ConnOptions) error {
+	return a.AddClusterWithOptions(opt, key, driver, dsn)
+}
+
+// 모든 primary가 연결된 상태인지
+func (a *App) Ready() bool {
+	for _, cl := range a.Clusters {
+		if !cl.Primary.Healthy() {
+			return false
+		}
+	}
+	return true
+}
+
+// readiness 프로브용 핸들러
+func Readiness(c *Context) {
+	if !c.App.Ready() {
+		// 오케스트레이터(k8s 등)는 상태코드로 판단
+		NewAppError("NotReady", nil, nil).WithStatus(http.StatusServiceUnavailable).Panic()
+	}
+	c.Response.Data = "Ready"
+}
+
+// 클러스터 가져오기
+func (a *App) GetCluster(key string) *Cluster {
+	return a.Clusters[key]
+}
+
+/*
+조회성 쿼리 판별: SELECT/SHOW/DESCRIBE/EXPLAIN 및 SPS_ 프로시저
+  - 잠금 읽기(FOR UPDATE 등)는 primary. 문자열 리터럴 안의 같은 글자는 무시
+  - WITH 로 시작하는 CTE 는 뒤에 UPDATE/DELETE 가 올 수 있으므로 primary
+*/
+func isReadQuery(query string) bool {
+	q := strings.ToUpper(strings.TrimLeft(query, " \t\r\n("))
+	bare := strings.Join(strings.Fields(stripQuoted(q)), " ")
+	if strings.Contains(bare, "FOR UPDATE") || strings.Contains(bare, "LOCK IN SHARE MODE") {
+		return false
+	}
+	for _, p := range []string{"SELECT", "SHOW", "DESCRIBE", "DESC ", "EXPLAIN"} {
+		if strings.HasPrefix(q, p) {
+			return true
+		}
+	}
+	if strings.HasPrefix(q, "CALL") {
+		proc := strings.TrimLeft(q[len("CALL"):], " \t\r\n`")
+		return strings.HasPrefix(proc, "SPS_")
+	}
+	return false
+}
+
+// 따옴표 문자열을 ” 로 바꾼 쿼리 (SplitSQL 과 같은 따옴표 규칙)
+func stripQuoted(q string) string {
+	if !strings.ContainsAny(q, "'\"`") {
+		return q
+	}
+	var b strings.Builder
+	for i := 0; i < len(q); {
+		if ch := q[i]; ch == '\'' || ch == '"' || ch == '`' {
+			i = quoteEnd(q, i)
+			b.WriteString("''")
+			continue
+		}
+		b.WriteByte(q[i])
+		i++
+	}
+	return b.String()
+}
+
+// 쿼리 종류와 요청 상태에 따라 사용할 커넥션 선택
+func (c *Context) pickDB(key, query string) *sql.DB {
+	cl, ok := c.App.Clusters[key]
+	if !ok {
+		db, ok := c.App.Conns[key]
+		if !ok {
+			NewAppError("ConnNotFound", fmt.Errorf("connection %q not registered", key), map[string]any{"Key": key}).Panic()
+		}
+		return db
+	}
+
+	read := isReadQuery(query)
+	if read && !(c.StickyWrites && c.wrote[key]) {
+		if db := cl.Reader(); db != cl.Primary.DB || cl.Primary.Healthy() {
+			return db
+		}
+	}
+	// 쓰기, 또는 건강한 replica 가 없는 읽기: primary 가 죽었으면 읽기/쓰기 모두 ConnUnavailable
+	if !cl.Primary.Healthy() {
+		NewAppError("ConnUnavailable", nil, map[string]any{"Key": key}).
+			WithStatus(http.StatusServiceUnavailable).Panic()
+	}
+	if !read && c.StickyWrites {
+		c.wrote[key] = true
+	}
+	return cl.Writer()
+}
+
+// 쿼리 실행 (조회는 replica, 그 외는 primary)
+func (c *Context) Query(key, query string, args ...any) *sql.Rows {
+	start := time.Now()
+	rows, err := c.pickDB(key, query).QueryContext(c.Ctx, query, args...)
+	c.traceSQL(key, query, args, start, nil, err)
+	if err != nil {
+		dbError(key, err).Panic()
+	}
+	return rows
+}
+
+func (c *Context) QueryRow(key, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := c.pickDB(key, query).QueryRowContext(c.Ctx, query, args...)
+	c.traceSQL(key, query, args, start, nil, row.Err())
+	return row
+}
+
+func (c *Context) Exec(key, query string, args ...any) sql.Result {
+	start := time.Now()
+	res, err := c.pickDB(key, query).ExecContext(c.Ctx, query, args...)
+	c.traceSQL(key, query, args, start, res, err)
+	if err != nil {
+		dbError(key, err).Panic()
+	}
+	return res
+}
+
+// 프로시저 호출: SPS_ 는 replica, 나머지는 primary
+func (c *Context) Call(key, proc string, args ...any) *sql.Rows {
+	return c.Query(key, callQuery(proc, len(args)), args...)
+}
+
+// 요청 시간 초과로 취소된 쿼리는 Timeout, 그 외는 DBError
+func dbError(key string, err error) *AppError {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return timeoutError(err, map[string]any{"Key": key})
+	}
+	return NewAppError("DBError", err, map[string]any{"Key": key})
+}
+
+func callQuery(proc string, n int) string {
+	marks := strings.TrimSuffix(strings.Repeat("?,", n), ",")
+	return fmt.Sprintf("CALL %s(%s)", proc, marks)
+}
+
+/*
+SQL 실행 로그. 핸들러 CALL 로그 아래에 쿼리 단위로 남김
+  - Debug: 모든 쿼리 (인자는 타입/길이만 남기고 값은 가림)
+  - Warn: App.SlowQuery 이상 걸린 쿼리
+*/
+func (c *Context) traceSQL(key, query string, args []any, start time.Time, res sql.Result, err error) {
+	elapsed := time.Since(start)
+	slow := c.App.SlowQuery > 0 && elapsed >= c.App.SlowQuery
+	if !slow && !c.App.Logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+
+	attrs := []any{
+		"Key", key,
+		"Query", query,
+		"Args", redactArgs(args),
+		"Elapsed", elapsed.String(),
+	}
+	if res != nil {
+		if n, err := res.RowsAffected(); err == nil {
+			attrs = append(attrs, "RowsAffected", n)
+		}
+	}
+	if err != nil {
+		attrs = append(attrs, "err", err.Error())
+	}
+
+	if slow {
+		c.App.Logger.Warn(c.PrependXReqID("SLOW SQL"), attrs...)
+	} else {
+		c.App.Logger.Debug(c.PrependXReqID("SQL"), attrs...)
+	}
+}
+
+// 로그에 값이 남지 않도록 인자를 타입(길이)로 치환
+func redactArgs(args []any) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			out[i] = "nil"
+		case string:
+			out[i] = fmt.Sprintf("string(%d)", len(v))
+		case []byte:
+			out[i] = fmt.Sprintf("[]byte(%d)", len(v))
+		default:
+			out[i] = fmt.Sprintf("%T", v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package x
+
+import "net/http"
+
+/*
+응답 봉투 생성기. 상태코드, Content-Type(비어있으면 형식 기본값), 직렬화할 값을 반환
+App.Envelope 가 기본이고 라우트별로 WithEnvelope 로 바꿀 수 있음
+
+	// {"status":"OK","result":...,"error":{"code":...}} 형태 예시
+	func Partner(c *x.Context) (int, string, any) {
+		body := map[string]any{"status": c.Response.Code, "result": c.Response.Data}
+		if c.Response.Code != "OK" {
+			body["error"] = map[string]any{"code": c.Response.Code, "message": c.Response.Message}
+		}
+		return http.StatusOK, "", body
+	}
+*/
+type Envelope func(c *Context) (status int, contentType string, body any)
+
+// 기본 봉투 {Code, Message, Data, Elapsed}
+func DefaultEnvelope(c *Context) (int, string, any) {
+	return c.status(), "", c.Response
+}
+
+// RFC 7807 problem+json 본문
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	ReqID    string `json:"reqId"`
+	Errors   any    `json:"errors,omitempty"` // ValidationFailed 의 필드 오류
+}
+
+// 상태코드 없이 만들어지는 클라이언트 에러 코드 (앱 코드도 추가 가능, 예: "RecordNotFound": 404)
+// 여기 없고 상태코드도 없는 에러는 500
+var ProblemStatus = map[string]int{
+	"BindFailed":       http.StatusBadRequest,
+	"ValidationFailed": http.StatusBadRequest,
+}
+
+// 에러는 RFC 7807 problem+json, 성공은 기본 봉투
+func ProblemEnvelope(c *Context) (int, string, any) {
+	if c.Response.Code == "OK" {
+		return DefaultEnvelope(c)
+	}
+
+	status := c.status()
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+		if s, ok := ProblemStatus[c.Response.Code]; ok {
+			status = s
+		}
+	}
+	p := Problem{
+		Type:     "about:blank",
+		Title:    c.Response.Code,
+		Status:   status,
+		Detail:   c.Response.Message,
+		Instance: c.Req.URL.Path,
+		Code:     c.Response.Code,
+		ReqID:    c.ReqID,
+	}
+	if c.AppError != nil {
+		p.Errors = c.AppError.Data["Fields"]
+	}
+	return status, "application/problem+json; charset=utf-8", p
+}
+
+// 라우트 봉투 지정
+func (rt *Route) WithEnvelope(e Envelope) *Route {
+	rt.Envelope = e
+	return rt
+}
+
+// 라우트 봉투, 없으면 App 봉투
+func (c *Context) envelope() (int, string, any) {
+	if c.Route != nil && c.Route.Envelope != nil {
+		return c.Route.Envelope(c)
+	}
+	if c.App.Envelope != nil {
+		return c.App.Envelope(c)
+	}
+	return DefaultEnvelope(c)
+}
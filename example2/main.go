@@ -1,12 +1,26 @@
 package main
 
 import (
+	"embed"
+	"io/fs"
 	"log/slog"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/simjinhyun/x"
 )
 
+//go:embed migrations
+var migrations embed.FS
+
+//go:embed templates
+var templates embed.FS
+
+// 정적 파일도 실행파일에 포함 (단일 바이너리 배포)
+//
+//go:embed www
+var www embed.FS
+
 var (
 	Version  string
 	Revision string
@@ -14,18 +28,43 @@ var (
 )
 
 func main() {
-	a := x.NewApp("www")
+	webRoot, err := fs.Sub(www, "www")
+	if err != nil {
+		panic(err)
+	}
+	a := x.NewAppFS(webRoot)
 	a.SetLogger(slog.LevelDebug, "Asia/Seoul", "2006.01.02 15:04:05 (MST)")
 	a.Logger.Info("Build", "Version", Version)
 	a.Logger.Info("Build", "Revision", Revision)
 	a.Logger.Info("Build", "Date", Date)
-	a.AddConn(
-		"db1",
-		"mysql",
-		"root:Tldrmf#2013@tcp(10.0.0.200:3306)/testdb?timeout=5s&readTimeout=30s&writeTimeout=30s",
-	)
+	// 예) DB1_DSN="user:pass@tcp(host:3306)/testdb?parseTime=true&timeout=5s&readTimeout=30s&writeTimeout=30s"
+	// 또는 file:/run/secrets/db1_dsn, enc:... (x.EncryptSecret 결과 + X_MASTER_KEY)
+	a.AddConn("db1", "mysql", "env:DB1_DSN")
+	// ./example2 migrate up | down [N] | status
+	a.AddMigrations("db1", migrations, "migrations")
+
+	// 개발 중에는 os.DirFS(".") 와 dev=true 로 수정 즉시 반영
+	a.SetTemplates(templates, "templates", false).ErrorPage = "error"
+
+	// 1KB 이상 JSON/HTML/CSS/JS 응답은 gzip 압축
+	a.Router.Use(x.Compress(nil))
+
+	// 보안 헤더 (jQuery CDN 허용). index.html 의 onclick 속성을
+	// script.js 로 옮기기 전까지 CSP 는 차단하지 않고 보고만 함
+	sec := x.NewSecurityHeaders()
+	sec.CSP = "default-src 'self'; script-src 'self' https://code.jquery.com {nonce}; object-src 'none'; base-uri 'self'"
+	sec.CSPReportOnly = true
+	a.Router.Use(x.SecureHeaders(sec))
+
+	// CSRF 토큰은 세션에 저장 (세션 쿠키 암호화에 X_MASTER_KEY 필요)
+	a.SetSessions(x.NewMemorySessionStore())
+	// POST 등은 XSRF-TOKEN 쿠키 값을 X-XSRF-TOKEN 헤더로 보내야 함 (script.js 의 $.ajaxSetup)
+	a.Router.Use(x.CSRF(nil))
 
 	a.Router.AddRoute("POST", "/hello", x.ReplyJSON, MDW1, MDW2, MDW3, MDW4, MDW5, Hello)
+	a.Router.AddRoute("GET", "/page.html", x.ReplyHTML, Page)
+	// Accept: text/csv 또는 ?format=csv 이면 CSV 다운로드
+	a.Router.AddRoute("GET", "/address", x.ReplyAuto, AddressList)
 
 	a.Run("localhost:7000", 5)
 }
@@ -39,3 +78,36 @@ func Hello(c *x.Context) {
 	c.Response.Data = "Hello World"
 	c.Debug("xxx")
 }
+
+// 모달로 불러가는 페이지 조각
+func Page(c *x.Context) {
+	c.Render("page", map[string]any{
+		"Version": Version,
+		"Date":    Date,
+	})
+}
+
+type Address struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Age       int       `json:"age"`
+	Phone     *string   `json:"phone"`
+	Address   *string   `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// 주소록 전체 조회 (SPS_ 프로시저는 replica 로 라우팅)
+func AddressList(c *x.Context) {
+	rows := c.Call("db1", "SPS_address_book_all")
+	defer rows.Close()
+
+	list := []Address{}
+	for rows.Next() {
+		var a Address
+		if err := rows.Scan(&a.ID, &a.Name, &a.Age, &a.Phone, &a.Address, &a.CreatedAt); err != nil {
+			x.NewAppError("DBError", err, nil).Panic()
+		}
+		list = append(list, a)
+	}
+	c.Response.Data = list
+}
@@ -1,11 +1,4 @@
--- 데이터베이스 생성 (없으면 생성)
-CREATE DATABASE IF NOT EXISTS testdb;
-USE testdb;
-
--- 주소록 테이블이 있으면 삭제
-DROP TABLE IF EXISTS address_book;
-
--- 새로 테이블 생성 (created_at은 TIMESTAMP)
+-- 주소록 테이블 생성 (created_at은 TIMESTAMP)
 CREATE TABLE address_book (
     id INT AUTO_INCREMENT PRIMARY KEY,
     name VARCHAR(50) NOT NULL,
@@ -0,0 +1,4 @@
+DROP PROCEDURE IF EXISTS `SPI_address_book`;
+DROP PROCEDURE IF EXISTS `SPS_address_book_all`;
+DROP PROCEDURE IF EXISTS `SPU_address_book`;
+DROP PROCEDURE IF EXISTS `SPD_address_book`;
@@ -66,3 +66,16 @@ function showToast(msg) {
     Modal({ html: `<p>${msg}</p>` });
     setTimeout(closeModal, 2000);
 }
+
+// CSRF: 서버가 발급한 XSRF-TOKEN 쿠키 값을 헤더로 보냄 (GET 등 안전한 요청은 검사하지 않음)
+function getCookie(name) {
+    const m = document.cookie.match(new RegExp('(?:^|; )' + name + '=([^;]*)'));
+    return m ? decodeURIComponent(m[1]) : '';
+}
+$.ajaxSetup({
+    beforeSend: function (xhr, settings) {
+        if (!/^(GET|HEAD|OPTIONS|TRACE)$/i.test(settings.type)) {
+            xhr.setRequestHeader('X-XSRF-TOKEN', getCookie('XSRF-TOKEN'));
+        }
+    }
+});
@@ -0,0 +1,405 @@
+package x
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 마이그레이션 파일명: 0001_create_table.up.sql / 0001_create_table.down.sql
+// (.up/.down 이 없는 0001_name.sql 은 up 으로 취급)
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+?)(\.up|\.down)?\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string // up 스크립트 경로
+	Down    string // down 스크립트 경로 (없으면 롤백 불가)
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// 디렉토리(또는 embed.FS)의 .sql 파일을 버전 순서대로 적용
+type Migrator struct {
+	DB    *sql.DB
+	FS    fs.FS
+	Dir   string
+	Table string
+}
+
+func NewMigrator(db *sql.DB, fsys fs.FS, dir string) *Migrator {
+	return &Migrator{
+		DB:    db,
+		FS:    fsys,
+		Dir:   dir,
+		Table: "schema_migrations",
+	}
+}
+
+// 마이그레이션 파일 목록 (버전 오름차순)
+func (m *Migrator) Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(m.FS, m.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := migrationFile.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d used by %q and %q", version, mig.Name, match[2])
+		}
+		file := path.Join(m.Dir, e.Name())
+		if match[3] == ".down" {
+			mig.Down = file
+		} else {
+			mig.Up = file
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.DB.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s ("+
+			"version BIGINT NOT NULL PRIMARY KEY, "+
+			"name VARCHAR(255) NOT NULL, "+
+			"applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)",
+		m.Table,
+	))
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]bool, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := m.DB.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s", m.Table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := map[int64]bool{}
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		versions[v] = true
+	}
+	return versions, rows.Err()
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationStatus, len(migrations))
+	for i, mig := range migrations {
+		status[i] = MigrationStatus{Migration: mig, Applied: applied[mig.Version]}
+	}
+	return status, nil
+}
+
+// 적용되지 않은 마이그레이션을 모두 적용하고 적용된 목록을 반환
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, s := range status {
+		if s.Applied {
+			continue
+		}
+		if err := m.run(ctx, s.Up); err != nil {
+			return done, fmt.Errorf("migration %d_%s up: %w", s.Version, s.Name, err)
+		}
+		if _, err := m.DB.ExecContext(ctx, fmt.Sprintf(
+			"INSERT INTO %s (version, name) VALUES (?, ?)", m.Table,
+		), s.Version, s.Name); err != nil {
+			return done, err
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// 최근 적용된 마이그레이션부터 steps 개를 되돌림
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(status) - 1; i >= 0 && len(done) < steps; i-- {
+		s := status[i]
+		if !s.Applied {
+			continue
+		}
+		if s.Down == "" {
+			return done, fmt.Errorf("migration %d_%s has no down script", s.Version, s.Name)
+		}
+		if err := m.run(ctx, s.Down); err != nil {
+			return done, fmt.Errorf("migration %d_%s down: %w", s.Version, s.Name, err)
+		}
+		if _, err := m.DB.ExecContext(ctx, fmt.Sprintf(
+			"DELETE FROM %s WHERE version = ?", m.Table,
+		), s.Version); err != nil {
+			return done, err
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// 스크립트를 문장 단위로 나눠 같은 세션에서 순서대로 실행
+func (m *Migrator) run(ctx context.Context, file string) error {
+	b, err := fs.ReadFile(m.FS, file)
+	if err != nil {
+		return err
+	}
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, stmt := range SplitSQL(string(b)) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%w\n%s", err, stmt)
+		}
+	}
+	return nil
+}
+
+/*
+MySQL 클라이언트처럼 스크립트를 문장 단위로 분리
+  - DELIMITER 명령으로 구분자 변경 (프로시저 본문의 ; 보존)
+  - 따옴표('  "  `) 안의 구분자는 무시
+  - 주석(--, #)은 제거, 블록 주석은 유지
+*/
+func SplitSQL(script string) []string {
+	var stmts []string
+	var buf strings.Builder
+	delim := ";"
+
+	flush := func() {
+		if s := strings.TrimSpace(buf.String()); s != "" {
+			stmts = append(stmts, s)
+		}
+		buf.Reset()
+	}
+
+	lineStart := true
+	for i := 0; i < len(script); {
+		if lineStart {
+			j := i
+			for j < len(script) && (script[j] == ' ' || script[j] == '\t') {
+				j++
+			}
+			if isDelimiterCommand(script[j:]) {
+				end := lineEnd(script, j)
+				flush()
+				delim = strings.TrimSpace(script[j+len("DELIMITER") : end])
+				i = end
+				continue
+			}
+		}
+
+		ch := script[i]
+		rest := script[i:]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := quoteEnd(script, i)
+			buf.WriteString(script[i:end])
+			i = end
+			lineStart = false
+			continue
+		case ch == '#' || isLineComment(rest):
+			i = lineEnd(script, i)
+			continue
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			buf.WriteString(rest[:end])
+			i += end
+			lineStart = false
+			continue
+		case delim != "" && strings.HasPrefix(rest, delim):
+			flush()
+			i += len(delim)
+			lineStart = false
+			continue
+		}
+
+		buf.WriteByte(ch)
+		lineStart = ch == '\n'
+		i++
+	}
+	flush()
+	return stmts
+}
+
+func isDelimiterCommand(s string) bool {
+	const cmd = "DELIMITER"
+	if len(s) <= len(cmd) || !strings.EqualFold(s[:len(cmd)], cmd) {
+		return false
+	}
+	return s[len(cmd)] == ' ' || s[len(cmd)] == '\t'
+}
+
+// "-- " 뒤에는 공백/개행이 와야 주석 (MySQL 규칙)
+func isLineComment(s string) bool {
+	if !strings.HasPrefix(s, "--") {
+		return false
+	}
+	return len(s) == 2 || s[2] == ' ' || s[2] == '\t' || s[2] == '\r' || s[2] == '\n'
+}
+
+// 개행 문자 위치 (개행은 포함하지 않음)
+func lineEnd(s string, i int) int {
+	if n := strings.IndexByte(s[i:], '\n'); n >= 0 {
+		return i + n
+	}
+	return len(s)
+}
+
+// 따옴표 문자열의 끝 다음 위치. 백슬래시 이스케이프와 겹따옴표 처리
+func quoteEnd(s string, i int) int {
+	q := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			if q != '`' {
+				j++
+			}
+		case q:
+			if j+1 < len(s) && s[j+1] == q {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// 커넥션 키에 마이그레이션 디렉토리 등록 (AddConn 이후 호출)
+func (a *App) AddMigrations(key string, fsys fs.FS, dir string) {
+	db := a.GetConn(key)
+	if db == nil {
+		panic(fmt.Errorf("connection %q not registered", key))
+	}
+	a.Migrators[key] = NewMigrator(db, fsys, dir)
+}
+
+/*
+CLI 모드: 실행파일 migrate up | down [N] | status
+Run 에서 첫 인자가 migrate 이면 서버를 띄우지 않고 이 함수 결과로 종료
+*/
+func (a *App) Migrate(args ...string) int {
+	defer a.RemoveConns()
+
+	cmd := "status"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+	steps := 1
+	if cmd == "down" && len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			a.Logger.Error(PrependX("Migrate"), "err", "invalid step count", "arg", args[1])
+			return 2
+		}
+		steps = n
+	}
+
+	keys := make([]string, 0, len(a.Migrators))
+	for key := range a.Migrators {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ctx := context.Background()
+	for _, key := range keys {
+		m := a.Migrators[key]
+		var done []Migration
+		var err error
+
+		switch cmd {
+		case "up":
+			done, err = m.Up(ctx)
+		case "down":
+			done, err = m.Down(ctx, steps)
+		case "status":
+			var status []MigrationStatus
+			status, err = m.Status(ctx)
+			for _, s := range status {
+				state := "pending"
+				if s.Applied {
+					state = "applied"
+				}
+				fmt.Printf("%-10s %6d  %-8s %s\n", key, s.Version, state, s.Name)
+			}
+		default:
+			a.Logger.Error(PrependX("Migrate"), "err", "unknown command", "cmd", cmd)
+			return 2
+		}
+
+		for _, mig := range done {
+			a.Logger.Info(PrependX("Migration "+cmd), "key", key, "version", mig.Version, "name", mig.Name)
+		}
+		if err != nil {
+			a.Logger.Error(PrependX("Migrate"), "key", key, "err", err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package x
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			"simple statements",
+			"CREATE TABLE a (id INT);\nINSERT INTO a VALUES (1);\n",
+			[]string{"CREATE TABLE a (id INT)", "INSERT INTO a VALUES (1)"},
+		},
+		{
+			"missing final delimiter",
+			"SELECT 1;\nSELECT 2",
+			[]string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			"empty statements",
+			";;\n  ;\nSELECT 1;;",
+			[]string{"SELECT 1"},
+		},
+		{
+			"delimiter in single quotes",
+			"INSERT INTO a VALUES ('x;y');SELECT 2;",
+			[]string{"INSERT INTO a VALUES ('x;y')", "SELECT 2"},
+		},
+		{
+			"delimiter in double quotes and backticks",
+			"SELECT \"a;b\", `c;d` FROM t;SELECT 2;",
+			[]string{"SELECT \"a;b\", `c;d` FROM t", "SELECT 2"},
+		},
+		{
+			"backslash escaped quote",
+			`INSERT INTO a VALUES ('it\'s;ok');SELECT 2;`,
+			[]string{`INSERT INTO a VALUES ('it\'s;ok')`, "SELECT 2"},
+		},
+		{
+			"doubled quote",
+			"INSERT INTO a VALUES ('it''s;ok');SELECT 2;",
+			[]string{"INSERT INTO a VALUES ('it''s;ok')", "SELECT 2"},
+		},
+		{
+			"line comments removed",
+			"-- drop; everything\nSELECT 1; # trailing; comment\nSELECT 2;",
+			[]string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			"double dash without space is not a comment",
+			"SELECT 5--1;",
+			[]string{"SELECT 5--1"},
+		},
+		{
+			"block comment kept",
+			"SELECT /* a; b */ 1;",
+			[]string{"SELECT /* a; b */ 1"},
+		},
+		{
+			"delimiter around procedure body",
+			"DELIMITER $$\n" +
+				"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 'x;y';\nEND$$\n" +
+				"DELIMITER ;\n" +
+				"CALL p();\n",
+			[]string{
+				"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 'x;y';\nEND",
+				"CALL p()",
+			},
+		},
+		{
+			"lowercase indented delimiter command",
+			"  delimiter //\nCREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN SET NEW.id = 1; END//\ndelimiter ;\nSELECT 1;",
+			[]string{"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN SET NEW.id = 1; END", "SELECT 1"},
+		},
+		{
+			"delimiter word inside a statement",
+			"SELECT 'DELIMITER $$';\nSELECT 2;",
+			[]string{"SELECT 'DELIMITER $$'", "SELECT 2"},
+		},
+		{
+			"procedure body without final delimiter",
+			"DELIMITER $$\nCREATE PROCEDURE p() BEGIN SELECT 1; END",
+			[]string{"CREATE PROCEDURE p() BEGIN SELECT 1; END"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitSQL(tt.script); !slices.Equal(got, tt.want) {
+				t.Errorf("SplitSQL(%q)\n got %q\nwant %q", tt.script, got, tt.want)
+			}
+		})
+	}
+}
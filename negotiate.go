@@ -0,0 +1,256 @@
+package x
+
+import (
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReplyAuto 가 고를 수 있는 형식 (같은 q 값이면 앞쪽 우선)
+var autoReplies = []struct {
+	mediaTypes []string
+	format     string
+	reply      HandlerFunc
+}{
+	{[]string{"application/json"}, "json", ReplyJSON},
+	{[]string{"text/html"}, "html", ReplyHTML},
+	{[]string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}, "msgpack", ReplyMsgpack},
+	{[]string{"text/csv"}, "csv", ReplyCSV},
+	{[]string{"text/plain"}, "text", ReplyText},
+}
+
+/*
+Accept 헤더로 응답 형식 선택 (JSON, HTML, MessagePack, CSV, Text)
+링크 다운로드처럼 Accept 를 못 바꾸는 경우 ?format=csv 로 지정 가능
+맞는 형식이 없으면 JSON
+*/
+func ReplyAuto(c *Context) {
+	c.Res.Header().Add("Vary", "Accept")
+	negotiate(c.Req)(c)
+}
+
+func negotiate(r *http.Request) HandlerFunc {
+	if format := r.URL.Query().Get("format"); format != "" {
+		for _, ar := range autoReplies {
+			if ar.format == format {
+				return ar.reply
+			}
+		}
+	}
+
+	best, bestQ := HandlerFunc(ReplyJSON), 0.0
+	for _, ar := range autoReplies {
+		for _, mt := range ar.mediaTypes {
+			if q := acceptQ(r.Header.Get("Accept"), mt); q > bestQ {
+				best, bestQ = ar.reply, q
+			}
+		}
+	}
+	return best
+}
+
+// Accept 헤더에서 mediaType 의 q 값 (가장 구체적인 항목 기준)
+func acceptQ(accept, mediaType string) float64 {
+	if accept == "" {
+		return 0
+	}
+	major, _, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		s := -1
+		switch mt {
+		case mediaType:
+			s = 2
+		case major + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		}
+		if s <= specificity {
+			continue
+		}
+		specificity, q = s, 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return q
+}
+
+func ReplyMsgpack(c *Context) {
+	status, _, body := c.envelope()
+	b, err := MarshalMsgpack(body)
+	if err != nil {
+		http.Error(c.Res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.Res.Header().Set("Content-Type", "application/msgpack")
+	c.Res.WriteHeader(status)
+	c.Res.Write(b)
+}
+
+// 텍스트 응답: 문자열은 그대로, 그 외는 fmt 형식. 에러면 코드만
+func ReplyText(c *Context) {
+	c.Res.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	c.Res.WriteHeader(c.status())
+	if c.Response.Code != "OK" {
+		fmt.Fprintf(c.Res, "Error: %s\n", c.Response.Code)
+		return
+	}
+	if s, ok := c.Response.Data.(string); ok {
+		fmt.Fprint(c.Res, s)
+		return
+	}
+	fmt.Fprintf(c.Res, "%+v\n", c.Response.Data)
+}
+
+/*
+CSV 다운로드. Data 는 [][]string, 구조체 슬라이스, map 슬라이스 지원
+구조체 컬럼명은 csv 태그, 없으면 json 태그, 없으면 필드명 (map 은 키 정렬)
+에러이거나 표 형태가 아니면 ReplyText
+수식으로 해석될 수 있는 셀(=, +, -, @ 로 시작)은 앞에 ' 를 붙임 (CSV injection 방지)
+*/
+func ReplyCSV(c *Context) {
+	records, ok := toRecords(c.Response.Data)
+	if c.Response.Code != "OK" || !ok {
+		ReplyText(c)
+		return
+	}
+
+	name := path.Base(c.Req.URL.Path)
+	if name == "/" || name == "." {
+		name = "data"
+	}
+	c.Res.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	c.Res.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name + ".csv"}))
+	c.Res.WriteHeader(c.status())
+
+	w := csv.NewWriter(c.Res)
+	for _, record := range records {
+		safe := make([]string, len(record))
+		for i, cell := range record {
+			safe[i] = csvCell(cell)
+		}
+		w.Write(safe)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		c.Warn("csv write failed", "err", err)
+	}
+}
+
+func toRecords(data any) ([][]string, bool) {
+	if records, ok := data.([][]string); ok {
+		return records, true
+	}
+
+	rv := reflect.ValueOf(data)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	elem := rv.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+
+	switch {
+	case elem.Kind() == reflect.Struct && elem != timeType:
+		var header []string
+		var index []int
+		for i := 0; i < elem.NumField(); i++ {
+			f := elem.Field(i)
+			if !f.IsExported() || f.Tag.Get("csv") == "-" {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("csv"), ",")
+			if name == "" {
+				name = fieldName(f)
+			}
+			header = append(header, name)
+			index = append(index, i)
+		}
+		records := [][]string{header}
+		for i := 0; i < rv.Len(); i++ {
+			row := reflect.Indirect(rv.Index(i))
+			record := make([]string, len(index))
+			if row.IsValid() {
+				for j, idx := range index {
+					record[j] = csvValue(row.Field(idx))
+				}
+			}
+			records = append(records, record)
+		}
+		return records, true
+
+	case elem.Kind() == reflect.Map && elem.Key().Kind() == reflect.String:
+		keySet := map[string]bool{}
+		for i := 0; i < rv.Len(); i++ {
+			for _, k := range rv.Index(i).MapKeys() {
+				keySet[k.String()] = true
+			}
+		}
+		header := make([]string, 0, len(keySet))
+		for k := range keySet {
+			header = append(header, k)
+		}
+		sort.Strings(header)
+
+		records := [][]string{header}
+		for i := 0; i < rv.Len(); i++ {
+			m := rv.Index(i)
+			record := make([]string, len(header))
+			for j, k := range header {
+				record[j] = csvValue(m.MapIndex(reflect.ValueOf(k).Convert(elem.Key())))
+			}
+			records = append(records, record)
+		}
+		return records, true
+	}
+	return nil, false
+}
+
+// 엑셀 등에서 수식으로 실행되지 않게 = + - @ 탭 CR 로 시작하는 값은 ' 를 붙임 (음수 등 숫자는 그대로)
+func csvCell(s string) string {
+	if s == "" || !strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return s
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+	return "'" + s
+}
+
+func csvValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return x.Format(time.RFC3339)
+	case []byte:
+		return string(x)
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,157 @@
+package x
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// 신뢰할 프록시 등록 (CIDR 또는 단일 IP). 잘못된 값이면 패닉
+func (a *App) SetTrustedProxies(cidrs ...string) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				panic(fmt.Errorf("invalid trusted proxy %q", cidr))
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(fmt.Errorf("invalid trusted proxy %q: %w", cidr, err))
+		}
+		nets = append(nets, n)
+	}
+	a.TrustedProxies = nets
+	a.Logger.Info(PrependX("Trusted proxies"), "CIDRs", cidrs)
+}
+
+func isTrusted(ip net.IP, trusted []*net.IPNet) bool {
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// HTTPS 요청인지. 신뢰 프록시를 거친 요청은 X-Forwarded-Proto / Forwarded proto= 를 봄
+func (c *Context) IsHTTPS() bool {
+	if c.Req.TLS != nil {
+		return true
+	}
+	peer, _, err := net.SplitHostPort(c.Req.RemoteAddr)
+	if err != nil {
+		peer = c.Req.RemoteAddr
+	}
+	if ip := net.ParseIP(peer); ip == nil || !isTrusted(ip, c.App.TrustedProxies) {
+		return false
+	}
+	if proto, _, _ := strings.Cut(c.Req.Header.Get("X-Forwarded-Proto"), ","); proto != "" {
+		return strings.EqualFold(strings.TrimSpace(proto), "https")
+	}
+	for _, v := range c.Req.Header.Values("Forwarded") {
+		elem, _, _ := strings.Cut(v, ",")
+		for _, pair := range strings.Split(elem, ";") {
+			key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "proto") {
+				return strings.EqualFold(strings.Trim(val, `"`), "https")
+			}
+		}
+	}
+	return false
+}
+
+/*
+클라이언트 IP. 직접 접속한 peer 가 신뢰 프록시일 때만 전달 헤더를 봄
+  - Forwarded (RFC 7239), X-Forwarded-For: 오른쪽부터 신뢰 프록시를 건너뛰고 첫 외부 IP
+  - X-Real-IP, CF-Connecting-IP: 위 헤더가 없을 때
+*/
+func getClientIP(r *http.Request, trusted []*net.IPNet) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	peerIP := net.ParseIP(peer)
+	if peerIP == nil || !isTrusted(peerIP, trusted) {
+		return peer
+	}
+
+	if chain := forwardedFor(r.Header.Values("Forwarded")); len(chain) > 0 {
+		return walkChain(chain, peer, trusted)
+	}
+
+	var chain []string
+	for _, xff := range r.Header.Values("X-Forwarded-For") {
+		for _, ip := range strings.Split(xff, ",") {
+			chain = append(chain, strings.TrimSpace(ip))
+		}
+	}
+	if len(chain) > 0 {
+		return walkChain(chain, peer, trusted)
+	}
+
+	for _, h := range []string{"X-Real-IP", "CF-Connecting-IP"} {
+		if v := strings.TrimSpace(r.Header.Get(h)); net.ParseIP(v) != nil {
+			return v
+		}
+	}
+	return peer
+}
+
+// 오른쪽(가장 가까운 hop)부터 신뢰 프록시를 건너뛰고 처음 만나는 외부 IP
+// 해석 불가한 값을 만나면 그 직전 hop 을 클라이언트로 봄
+func walkChain(chain []string, peer string, trusted []*net.IPNet) string {
+	last := peer
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := net.ParseIP(chain[i])
+		if ip == nil {
+			return last
+		}
+		if !isTrusted(ip, trusted) {
+			return chain[i]
+		}
+		last = chain[i]
+	}
+	return last
+}
+
+// Forwarded 헤더의 for= 값 목록 (포트/대괄호/따옴표 제거)
+//
+//	Forwarded: for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711"
+func forwardedFor(values []string) []string {
+	var chain []string
+	for _, v := range values {
+		for _, elem := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(elem, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(key, "for") {
+					continue
+				}
+				chain = append(chain, forwardedNode(val))
+			}
+		}
+	}
+	return chain
+}
+
+func forwardedNode(v string) string {
+	v = strings.Trim(strings.TrimSpace(v), `"`)
+	if strings.HasPrefix(v, "[") {
+		if end := strings.Index(v, "]"); end > 0 {
+			return v[1:end]
+		}
+		return v
+	}
+	if host, _, err := net.SplitHostPort(v); err == nil {
+		return host
+	}
+	return v
+}
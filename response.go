@@ -0,0 +1,149 @@
+package x
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"path/filepath"
+)
+
+// 상태코드와 응답 여부를 기록하는 ResponseWriter
+type responseWriter struct {
+	http.ResponseWriter
+	status       int
+	written      bool
+	beforeHeader []func() // 헤더를 보내기 직전에 실행 (세션 저장 등)
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.written {
+		return
+	}
+	// 1xx 정보 응답은 여러 번 보낼 수 있음
+	if status >= 100 && status < 200 {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+	w.written = true
+	for _, fn := range w.beforeHeader {
+		fn()
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// http.ResponseController 가 원본에 접근할 수 있게
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.written {
+			w.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		w.written = true
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("hijack not supported")
+}
+
+// 응답 헤더를 보내기 직전(첫 쓰기)에 실행할 함수. 헤더를 더 설정할 수 있음
+func (c *Context) onHeader(fn func()) {
+	if w, ok := c.Res.(*responseWriter); ok {
+		w.beforeHeader = append(w.beforeHeader, fn)
+	}
+}
+
+// 핸들러가 이미 응답을 썼는지. 썼으면 Reply 는 생략됨
+func (c *Context) Written() bool {
+	if w, ok := c.Res.(*responseWriter); ok {
+		return w.written
+	}
+	return false
+}
+
+// 실제로 보낸 상태코드 (아직 안 보냈으면 0)
+func (c *Context) Status() int {
+	if w, ok := c.Res.(*responseWriter); ok {
+		return w.status
+	}
+	return 0
+}
+
+// 아래 응답 헬퍼는 Reply 봉투(Code/Message/Data) 없이 바로 응답함
+
+func (c *Context) JSON(status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		NewAppError("RuntimeError", err, nil).Panic()
+	}
+	c.Blob(status, "application/json; charset=utf-8", b)
+}
+
+func (c *Context) XML(status int, v any) {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		NewAppError("RuntimeError", err, nil).Panic()
+	}
+	c.Blob(status, "application/xml; charset=utf-8", append([]byte(xml.Header), b...))
+}
+
+func (c *Context) HTML(status int, html string) {
+	c.Blob(status, "text/html; charset=utf-8", []byte(html))
+}
+
+func (c *Context) Text(status int, text string) {
+	c.Blob(status, "text/plain; charset=utf-8", []byte(text))
+}
+
+func (c *Context) Blob(status int, contentType string, b []byte) {
+	c.Res.Header().Set("Content-Type", contentType)
+	c.Res.WriteHeader(status)
+	if _, err := c.Res.Write(b); err != nil {
+		c.Warn("response write failed", "err", err)
+	}
+}
+
+// 파일 응답 (Range, If-Modified-Since 처리는 http.ServeFile)
+func (c *Context) File(path string) {
+	http.ServeFile(c.Res, c.Req, path)
+}
+
+// 다운로드 응답. name 이 비어있으면 파일명 그대로
+func (c *Context) Attachment(path, name string) {
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	c.Res.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	c.File(path)
+}
+
+func (c *Context) Redirect(status int, url string) {
+	if status < 300 || status > 308 {
+		NewAppError("RuntimeError", fmt.Errorf("invalid redirect status %d", status), nil).Panic()
+	}
+	http.Redirect(c.Res, c.Req, url, status)
+}
+
+func (c *Context) NoContent(status int) {
+	c.Res.WriteHeader(status)
+}
@@ -1,16 +1,38 @@
 package x
 
 import (
+	"context"
+	"errors"
+	"io/fs"
 	"net/http"
 	"os"
-	"path/filepath"
+	"path"
 	"reflect"
 	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 type Router struct {
-	WebRoot string
-	routes  map[string]*Route
+	WebRoot string // 디스크 경로 (FS 가 없을 때 사용)
+	FS      fs.FS  // 설정되면 WebRoot 대신 여기서 서빙 (embed.FS 등)
+	Static  *Static
+	// index.html 없는 디렉토리 요청 응답 (기본 404, 403 도 가능)
+	DirListingStatus int
+	// 404 응답 핸들러 (nil 이면 기본 문구). 핸들러가 직접 응답을 써야 함
+	NotFound HandlerFunc
+	// history 모드 SPA 폴백 (nil 이면 사용 안 함)
+	SPA    *SPAFallback
+	routes map[string]*Route
+
+	// 라우트/정적 파일 모두에 먼저 실행되는 미들웨어 (Use)
+	middlewares     []HandlerFunc
+	middlewareNames []string
+
+	mu   sync.Mutex
+	root *os.Root // WebRoot 를 연 핸들 (심볼릭 링크로 밖을 못 나감)
 }
 
 func NewRouter(WebRoot string) *Router {
@@ -21,48 +43,295 @@ func NewRouter(WebRoot string) *Router {
 		panic(err)
 	}
 	return &Router{
-		WebRoot: WebRoot,
-		routes:  make(map[string]*Route),
+		WebRoot:          WebRoot,
+		Static:           NewStatic(),
+		DirListingStatus: http.StatusNotFound,
+		routes:           make(map[string]*Route),
+	}
+}
+
+// 디스크 대신 fs.FS(embed.FS 등)에서 정적 파일을 서빙하는 라우터
+func NewRouterFS(fsys fs.FS) *Router {
+	return &Router{
+		FS:               fsys,
+		Static:           NewStatic(),
+		DirListingStatus: http.StatusNotFound,
+		routes:           make(map[string]*Route),
 	}
 }
 
 type HandlerFunc func(*Context)
 
 type Route struct {
-	Path         string
-	Method       string
-	Reply        HandlerFunc
-	Handlers     []HandlerFunc
-	HandlerNames []string
+	Path           string
+	Method         string
+	Reply          HandlerFunc
+	Handlers       []HandlerFunc
+	HandlerNames   []string
+	Timeout        time.Duration // 0이 아니면 이 시간 후 요청 컨텍스트(DB 쿼리 포함) 취소
+	BodyLimit      int64         // 0이면 App.BodyLimit, 음수면 무제한
+	SpoolThreshold int64         // 0이면 App.SpoolThreshold
+	Streaming      bool          // true 이면 본문을 버퍼링하지 않음
+	Upload         UploadLimits
+	Envelope       Envelope // nil 이면 App.Envelope
+	CORS           *CORSPolicy
+}
+
+// 라우트 타임아웃 설정
+func (rt *Route) WithTimeout(d time.Duration) *Route {
+	rt.Timeout = d
+	return rt
+}
+
+// 본문 최대 크기 설정 (음수면 무제한)
+func (rt *Route) WithBodyLimit(n int64) *Route {
+	rt.BodyLimit = n
+	return rt
 }
 
-func (r *Router) AddRoute(method, path string, reply HandlerFunc, handlers ...HandlerFunc) {
+// n 바이트를 넘는 본문은 임시파일로 옮김
+func (rt *Route) WithSpool(n int64) *Route {
+	rt.SpoolThreshold = n
+	return rt
+}
+
+// 본문을 버퍼링하지 않고 핸들러가 c.Req.Body 를 직접 읽음
+func (rt *Route) WithStreaming() *Route {
+	rt.Streaming = true
+	return rt
+}
+
+func handlerNames(handlers []HandlerFunc) []string {
 	names := make([]string, len(handlers))
 	for i, h := range handlers {
 		names[i] = runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
 	}
-	r.routes[method+" "+path] = &Route{
+	return names
+}
+
+// 전역 미들웨어 등록. 라우트 매칭 전에 실행되므로 정적 파일 응답에도 적용됨
+func (r *Router) Use(handlers ...HandlerFunc) {
+	r.middlewares = append(r.middlewares, handlers...)
+	r.middlewareNames = append(r.middlewareNames, handlerNames(handlers)...)
+}
+
+func (r *Router) AddRoute(method, path string, reply HandlerFunc, handlers ...HandlerFunc) *Route {
+	route := &Route{
 		Path:         path,
 		Method:       method,
 		Reply:        reply,
 		Handlers:     handlers,
-		HandlerNames: names,
+		HandlerNames: handlerNames(handlers),
 	}
+	r.routes[method+" "+path] = route
+	return route
 }
 
 func (r *Router) ServeHTTP(c *Context) {
-	key := c.Req.Method + " " + c.Req.URL.Path
+	// 미들웨어가 에러로 중단해도 라우트의 Reply 로 응답하도록 먼저 매칭
+	route, ok := r.routes[c.Req.Method+" "+c.Req.URL.Path]
+	if ok {
+		c.Route = route
+	}
+
+	for i, h := range r.middlewares {
+		c.App.Logger.Debug(c.PrependXReqID("CALL " + r.middlewareNames[i]))
+		h(c)
+		if c.aborted {
+			return
+		}
+	}
+
+	if ok {
+		if route.Timeout > 0 {
+			c.Ctx, c.cancel = context.WithTimeout(c.Ctx, route.Timeout)
+			c.Req = c.Req.WithContext(c.Ctx)
+		}
+		if route.CORS != nil {
+			route.CORS.apply(c)
+		}
+		c.CopyBody()
+		for _, fn := range c.onBody {
+			fn()
+		}
 
-	if route, ok := r.routes[key]; ok {
 		// 등록된 핸들러들을 순서대로 실행
 		for i, h := range route.Handlers {
 			c.App.Logger.Debug(c.PrependXReqID("CALL " + route.HandlerNames[i]))
 			h(c)
+			if c.aborted {
+				return
+			}
 		}
 		return
 	}
 
+	// OPTIONS 라우트가 없어도 등록된 경로면 정적 파일로 넘기지 않고 직접 응답
+	if c.Req.Method == http.MethodOptions {
+		if methods := r.methods(c.Req.URL.Path); len(methods) > 0 {
+			r.options(c, methods)
+			return
+		}
+	}
+
 	// 등록된 라우트가 없으면 정적 파일 제공
-	path := filepath.Join(r.WebRoot, c.Req.URL.Path)
-	http.ServeFile(c.Res, c.Req, path)
+	name := path.Clean("/" + c.Req.URL.Path)
+	fsys, err := r.staticFS()
+	if err == nil {
+		// 디렉토리 목록은 만들지 않음 (index.html 을 디스크에 써둘 필요 없음)
+		err = r.Static.Serve(c, fsys, name)
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrDirListing) && r.DirListingStatus != http.StatusNotFound:
+		http.Error(c.Res, http.StatusText(r.DirListingStatus), r.DirListingStatus)
+	case errors.Is(err, fs.ErrNotExist) && r.SPA.match(c.Req):
+		if err := r.Static.Serve(c, fsys, r.SPA.File); err != nil {
+			c.Error("spa fallback failed", "File", r.SPA.File, "err", err)
+			r.notFound(c)
+		}
+	case errors.Is(err, ErrDirListing), errors.Is(err, ErrDenied), errors.Is(err, fs.ErrNotExist):
+		r.notFound(c)
+	default:
+		c.Error("static file failed", "Path", name, "err", err)
+		http.Error(c.Res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// 경로에 등록된 메서드 목록 (정렬)
+func (r *Router) methods(p string) []string {
+	var methods []string
+	for _, route := range r.routes {
+		if route.Path == p {
+			methods = append(methods, route.Method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// CORS 정책이 있는 라우트면 preflight 응답, 아니면 Allow 헤더만
+func (r *Router) options(c *Context, methods []string) {
+	if isPreflight(c.Req) {
+		for _, m := range methods {
+			if route := r.routes[m+" "+c.Req.URL.Path]; route.CORS != nil {
+				route.CORS.preflight(c, methods)
+				return
+			}
+		}
+	}
+	c.Res.Header().Set("Allow", strings.Join(append(methods, http.MethodOptions), ", "))
+	c.Res.WriteHeader(http.StatusNoContent)
+}
+
+/*
+경로 접두어와 미들웨어를 공유하는 라우트 묶음
+
+	api := a.Router.Group("/api", Auth).WithCORS(x.NewCORSPolicy("https://app.example.com"))
+	api.AddRoute("GET", "/users", x.ReplyJSON, UserList) // GET /api/users, Auth → UserList
+*/
+type Group struct {
+	router   *Router
+	prefix   string
+	handlers []HandlerFunc
+	CORS     *CORSPolicy
+}
+
+func (r *Router) Group(prefix string, handlers ...HandlerFunc) *Group {
+	return &Group{
+		router:   r,
+		prefix:   strings.TrimSuffix(prefix, "/"),
+		handlers: handlers,
+	}
+}
+
+// 하위 묶음 (접두어, 미들웨어, CORS 정책 상속)
+func (g *Group) Group(prefix string, handlers ...HandlerFunc) *Group {
+	sub := g.router.Group(g.prefix+prefix, append(append([]HandlerFunc{}, g.handlers...), handlers...)...)
+	sub.CORS = g.CORS
+	return sub
+}
+
+// 묶음 미들웨어 추가 (이후 등록하는 라우트부터 적용)
+func (g *Group) Use(handlers ...HandlerFunc) *Group {
+	g.handlers = append(g.handlers, handlers...)
+	return g
+}
+
+// 이후 등록하는 라우트의 CORS 정책
+func (g *Group) WithCORS(p *CORSPolicy) *Group {
+	p.validate()
+	g.CORS = p
+	return g
+}
+
+func (g *Group) AddRoute(method, path string, reply HandlerFunc, handlers ...HandlerFunc) *Route {
+	all := append(append([]HandlerFunc{}, g.handlers...), handlers...)
+	route := g.router.AddRoute(method, g.prefix+path, reply, all...)
+	route.CORS = g.CORS
+	return route
+}
+
+// 정적 파일 FS. 디스크는 os.Root 로 열어 WebRoot 밖으로 나가는 심볼릭 링크 차단
+func (r *Router) staticFS() (fs.FS, error) {
+	if r.FS != nil {
+		return r.FS, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.root == nil || r.root.Name() != r.WebRoot {
+		root, err := os.OpenRoot(r.WebRoot)
+		if err != nil {
+			return nil, err
+		}
+		if r.root != nil {
+			r.root.Close()
+		}
+		r.root = root
+	}
+	return r.root.FS(), nil
+}
+
+// 라우트/파일이 없는 페이지 요청에 돌려줄 파일 (Vue/React history 모드)
+type SPAFallback struct {
+	File    string   // 폴백 파일 ("/index.html")
+	Exclude []string // 폴백하지 않을 경로 접두어 ("/api/")
+}
+
+// SPA 폴백 설정. file 이 비어있으면 /index.html
+func (r *Router) SetSPA(file string, exclude ...string) {
+	if file == "" {
+		file = "/index.html"
+	}
+	r.SPA = &SPAFallback{
+		File:    path.Clean("/" + file),
+		Exclude: exclude,
+	}
+}
+
+// GET/HEAD 이고 text/html 을 명시적으로 받는 요청(브라우저 페이지 이동)만 폴백
+func (s *SPAFallback) match(req *http.Request) bool {
+	if s == nil {
+		return false
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if !strings.Contains(req.Header.Get("Accept"), "text/html") {
+		return false
+	}
+	for _, prefix := range s.Exclude {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Router) notFound(c *Context) {
+	if r.NotFound != nil {
+		r.NotFound(c)
+		return
+	}
+	http.NotFound(c.Res, c.Req)
 }
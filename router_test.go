@@ -0,0 +1,58 @@
+package x
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareErrorStatus(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "page.txt"), []byte("page"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := NewApp(root)
+	a.SetLogger(slog.LevelError+1, "", "")
+	a.Router.AddRoute(http.MethodGet, "/api/me", ReplyJSON, func(c *Context) {
+		c.Response.Data = "me"
+	})
+	a.Router.Use(func(c *Context) {
+		if c.Req.Header.Get("Authorization") == "" {
+			NewAppError("Unauthorized", nil, nil).WithStatus(http.StatusUnauthorized).Panic()
+		}
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		auth   string
+		status int
+		body   string
+	}{
+		{"api route", "/api/me", "", http.StatusUnauthorized, "Unauthorized"},
+		{"static path", "/page.txt", "", http.StatusUnauthorized, "Unauthorized"},
+		{"unknown path", "/missing", "", http.StatusUnauthorized, "Unauthorized"},
+		{"api route authorized", "/api/me", "Bearer t", http.StatusOK, "me"},
+		{"static path authorized", "/page.txt", "Bearer t", http.StatusOK, "page"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			a.Server.Handler.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("GET %s = %d, want %d", tt.target, rec.Code, tt.status)
+			}
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("GET %s body = %q, want it to contain %q", tt.target, rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package x
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/simjinhyun/x/util"
+)
+
+// 암호화된 값(enc:)을 풀 때 사용하는 마스터키 환경변수 (base64, 16/24/32바이트)
+const MasterKeyEnv = "X_MASTER_KEY"
+
+/*
+소스코드에 비밀번호를 두지 않기 위한 참조 해석
+  - env:NAME   환경변수 값
+  - file:PATH  파일 내용 (Docker/K8s secret 등, 끝의 공백/개행 제거)
+  - enc:VALUE  util.AESGCMEncrypt 결과를 마스터키로 복호화 (VALUE 자체도 env:/file: 가능)
+
+접두어가 없으면 값을 그대로 돌려줌
+*/
+func ResolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, "env:"):
+		name := strings.TrimPrefix(ref, "env:")
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret env %q not set", name)
+		}
+		return v, nil
+
+	case strings.HasPrefix(ref, "file:"):
+		b, err := os.ReadFile(strings.TrimPrefix(ref, "file:"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), " \t\r\n"), nil
+
+	case strings.HasPrefix(ref, "enc:"):
+		cipherText, err := ResolveSecret(strings.TrimPrefix(ref, "enc:"))
+		if err != nil {
+			return "", err
+		}
+		key, err := masterKey()
+		if err != nil {
+			return "", err
+		}
+		return util.AESGCMDecrypt(cipherText, key)
+	}
+	return ref, nil
+}
+
+// 마스터키로 암호화해서 enc: 참조 문자열로 반환 (설정 파일에 넣을 값 생성용)
+func EncryptSecret(plain string) (string, error) {
+	key, err := masterKey()
+	if err != nil {
+		return "", err
+	}
+	cipherText, err := util.AESGCMEncrypt(plain, key)
+	if err != nil {
+		return "", err
+	}
+	return "enc:" + cipherText, nil
+}
+
+func masterKey() ([]byte, error) {
+	v := os.Getenv(MasterKeyEnv)
+	if v == "" {
+		return nil, fmt.Errorf("master key env %q not set", MasterKeyEnv)
+	}
+	key, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("master key env %q: %w", MasterKeyEnv, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,278 @@
+package x
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/simjinhyun/x/util"
+)
+
+// 세션 저장소. 데이터는 직렬화된 바이트로 주고받음
+type SessionStore interface {
+	// 없거나 만료됐으면 nil, nil
+	Load(ctx context.Context, id string) ([]byte, error)
+	Save(ctx context.Context, id string, data []byte, expires time.Time) error
+	Delete(ctx context.Context, id string) error
+}
+
+/*
+세션 설정. a.SetSessions(store) 후 핸들러에서 c.Session()
+  - 쿠키에는 세션 ID 를 AES-GCM 으로 암호화해서 담음 (위조/추측 불가)
+  - IdleTimeout 동안 요청이 없거나 AbsoluteTimeout 이 지나면 새 세션
+  - 로그인/권한 변경 시 s.Regenerate() 로 ID 교체 (세션 고정 공격 방지)
+*/
+type SessionManager struct {
+	Store           SessionStore
+	CookieName      string
+	Key             []byte // 쿠키 암호화 키 (16/24/32바이트)
+	IdleTimeout     time.Duration
+	AbsoluteTimeout time.Duration
+	TouchInterval   time.Duration // 값이 안 바뀐 세션은 이 간격으로만 만료시각 갱신 저장
+}
+
+// 세션 등록. 키는 마스터키(X_MASTER_KEY), 없으면 패닉
+func (a *App) SetSessions(store SessionStore) *SessionManager {
+	key, err := masterKey()
+	if err != nil {
+		panic(err)
+	}
+	a.Sessions = &SessionManager{
+		Store:           store,
+		CookieName:      "X_SESSION",
+		Key:             key,
+		IdleTimeout:     30 * time.Minute,
+		AbsoluteTimeout: 12 * time.Hour,
+		TouchInterval:   time.Minute,
+	}
+	return a.Sessions
+}
+
+// 세션 값은 JSON 으로 저장됨 (숫자는 float64 로 읽힘, Get[T] 대신 GetString 등 사용)
+type Session struct {
+	ID       string
+	Values   map[string]any
+	Created  time.Time
+	LastSeen time.Time
+
+	m         *SessionManager
+	c         *Context
+	isNew     bool
+	changed   bool
+	destroyed bool
+	oldID     string // Regenerate 전 ID (저장 시 삭제)
+}
+
+type sessionRecord struct {
+	Values   map[string]any
+	Created  time.Time
+	LastSeen time.Time
+}
+
+// 이번 요청의 세션 (처음 호출할 때 로드, 없으면 새로 만들고 쿠키 발급)
+func (c *Context) Session() *Session {
+	if c.session != nil {
+		return c.session
+	}
+	m := c.App.Sessions
+	if m == nil {
+		NewAppError("SessionNotConfigured", nil, nil).Panic()
+	}
+	c.session = m.load(c)
+	// 응답 헤더가 나가기 전에 저장 (클라이언트의 다음 요청이 이전 상태를 읽지 않게)
+	c.onHeader(c.session.persist)
+	return c.session
+}
+
+func (m *SessionManager) load(c *Context) *Session {
+	now := time.Now()
+	if ck, err := c.Req.Cookie(m.CookieName); err == nil {
+		if id, err := util.AESGCMDecrypt(ck.Value, m.Key); err == nil {
+			data, err := m.Store.Load(c.Ctx, id)
+			if err != nil {
+				NewAppError("SessionLoadFailed", err, nil).Panic()
+			}
+			var rec sessionRecord
+			if data != nil && json.Unmarshal(data, &rec) == nil {
+				s := &Session{ID: id, Values: rec.Values, Created: rec.Created, LastSeen: rec.LastSeen, m: m, c: c}
+				if s.Values == nil {
+					s.Values = map[string]any{}
+				}
+				if !s.expired(now) {
+					return s
+				}
+				// 만료된 세션은 지우고 새로 시작
+				if err := m.Store.Delete(c.Ctx, id); err != nil {
+					c.Warn("session delete failed", "err", err)
+				}
+			}
+		}
+	}
+
+	s := &Session{
+		ID:       newSessionID(),
+		Values:   map[string]any{},
+		Created:  now,
+		LastSeen: now,
+		m:        m,
+		c:        c,
+		isNew:    true,
+	}
+	s.setCookie()
+	return s
+}
+
+func (s *Session) expired(now time.Time) bool {
+	if s.m.IdleTimeout > 0 && now.Sub(s.LastSeen) > s.m.IdleTimeout {
+		return true
+	}
+	return s.m.AbsoluteTimeout > 0 && now.Sub(s.Created) > s.m.AbsoluteTimeout
+}
+
+func (s *Session) expires() time.Time {
+	exp := s.LastSeen.Add(s.m.IdleTimeout)
+	if s.m.IdleTimeout <= 0 {
+		exp = s.Created.Add(s.m.AbsoluteTimeout)
+	}
+	if abs := s.Created.Add(s.m.AbsoluteTimeout); s.m.AbsoluteTimeout > 0 && abs.Before(exp) {
+		exp = abs
+	}
+	return exp
+}
+
+// 세션 쿠키 발급. 본문을 쓰기 전에 헤더에 넣어야 하므로 생성/교체 시점에 바로 설정
+func (s *Session) setCookie() {
+	if s.c.Written() {
+		s.c.Warn("session cookie not sent: response already written")
+		return
+	}
+	value, err := util.AESGCMEncrypt(s.ID, s.m.Key)
+	if err != nil {
+		NewAppError("SessionSaveFailed", err, nil).Panic()
+	}
+	ck := util.SessionCookie(s.m.CookieName, value)
+	ck.Secure = s.c.IsHTTPS()
+	s.dropCookie()
+	http.SetCookie(s.c.Res, ck)
+}
+
+// 같은 요청에서 먼저 설정한 세션 쿠키 헤더 제거 (생성 직후 Regenerate 등)
+func (s *Session) dropCookie() {
+	h := s.c.Res.Header()
+	var kept []string
+	for _, v := range h.Values("Set-Cookie") {
+		if !strings.HasPrefix(v, s.m.CookieName+"=") {
+			kept = append(kept, v)
+		}
+	}
+	h.Del("Set-Cookie")
+	for _, v := range kept {
+		h.Add("Set-Cookie", v)
+	}
+}
+
+func (s *Session) Get(key string) any {
+	return s.Values[key]
+}
+
+func (s *Session) GetString(key string) string {
+	v, _ := s.Values[key].(string)
+	return v
+}
+
+func (s *Session) Set(key string, value any) {
+	s.Values[key] = value
+	s.changed = true
+}
+
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+	s.changed = true
+}
+
+// 값은 유지하고 ID 만 교체 (로그인 직후, 응답을 쓰기 전에 호출)
+func (s *Session) Regenerate() {
+	if s.c.Written() {
+		// 새 쿠키를 보낼 수 없으므로 기존 세션 유지
+		s.c.Warn("session not regenerated: response already written")
+		return
+	}
+	if !s.isNew && s.oldID == "" {
+		s.oldID = s.ID
+	}
+	s.ID = newSessionID()
+	s.changed = true
+	s.setCookie()
+}
+
+// 세션 삭제와 쿠키 만료 (로그아웃)
+func (s *Session) Destroy() {
+	s.destroyed = true
+	s.Values = map[string]any{}
+	if !s.c.Written() {
+		s.dropCookie()
+		util.ClearCookie(s.c.Res, s.m.CookieName)
+	}
+}
+
+// 에러(패닉)로 끝난 요청의 변경은 저장하지 않음
+func (s *Session) persist() {
+	if e := s.c.AppError; e != nil && e != noErr {
+		return
+	}
+	s.save()
+}
+
+// 첫 쓰기 직전과 Recover 에서 호출. 저장한 뒤 바뀐 것이 없으면 아무것도 안 함
+// 요청 컨텍스트가 취소돼도 저장
+func (s *Session) save() {
+	ctx := context.WithoutCancel(s.c.Ctx)
+	store := s.m.Store
+
+	if s.oldID != "" {
+		if err := store.Delete(ctx, s.oldID); err != nil {
+			s.c.Warn("session delete failed", "err", err)
+		}
+		s.oldID = ""
+	}
+	if s.destroyed {
+		if !s.isNew {
+			if err := store.Delete(ctx, s.ID); err != nil {
+				s.c.Warn("session delete failed", "err", err)
+			}
+			s.isNew = true // 다시 지우지 않게
+		}
+		return
+	}
+
+	now := time.Now()
+	// 빈 새 세션은 저장하지 않음 (쿠키만 나간 봇 요청 등)
+	if s.isNew && len(s.Values) == 0 {
+		return
+	}
+	if !s.isNew && !s.changed && now.Sub(s.LastSeen) < s.m.TouchInterval {
+		return
+	}
+	s.LastSeen = now
+
+	data, err := json.Marshal(sessionRecord{Values: s.Values, Created: s.Created, LastSeen: s.LastSeen})
+	if err != nil {
+		s.c.Error("session encode failed", "err", err)
+		return
+	}
+	if err := store.Save(ctx, s.ID, data, s.expires()); err != nil {
+		s.c.Error("session save failed", "err", err)
+		return
+	}
+	s.isNew, s.changed = false, false
+}
+
+func newSessionID() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
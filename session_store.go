@@ -0,0 +1,254 @@
+package x
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 만료된 세션 정리 간격 (저장할 때 이 간격이 지났으면 정리)
+const sessionSweepInterval = 10 * time.Minute
+
+var sessionIDPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// 메모리 세션 저장소 (단일 인스턴스, 재시작 시 사라짐)
+type MemorySessionStore struct {
+	mu      sync.Mutex
+	entries map[string]memorySession
+	swept   time.Time
+}
+
+type memorySession struct {
+	data    []byte
+	expires time.Time
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{entries: map[string]memorySession{}}
+}
+
+func (st *MemorySessionStore) Load(ctx context.Context, id string) ([]byte, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	e, ok := st.entries[id]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(e.expires) {
+		delete(st.entries, id)
+		return nil, nil
+	}
+	return e.data, nil
+}
+
+func (st *MemorySessionStore) Save(ctx context.Context, id string, data []byte, expires time.Time) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	now := time.Now()
+	if now.Sub(st.swept) > sessionSweepInterval {
+		for k, e := range st.entries {
+			if now.After(e.expires) {
+				delete(st.entries, k)
+			}
+		}
+		st.swept = now
+	}
+	st.entries[id] = memorySession{data: data, expires: expires}
+	return nil
+}
+
+func (st *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	st.mu.Lock()
+	delete(st.entries, id)
+	st.mu.Unlock()
+	return nil
+}
+
+// 파일 세션 저장소. 세션마다 Dir/<ID>.json 하나
+type FileSessionStore struct {
+	Dir string
+
+	mu    sync.Mutex
+	swept time.Time
+}
+
+type fileSession struct {
+	Expires time.Time
+	Data    json.RawMessage
+}
+
+func NewFileSessionStore(dir string) *FileSessionStore {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		panic(err)
+	}
+	return &FileSessionStore{Dir: dir}
+}
+
+// ID 는 쿠키에서 복호화한 값이지만 경로로 쓰기 전에 형식 확인
+func (st *FileSessionStore) path(id string) (string, error) {
+	if !sessionIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid session id")
+	}
+	return filepath.Join(st.Dir, id+".json"), nil
+}
+
+func (st *FileSessionStore) Load(ctx context.Context, id string) ([]byte, error) {
+	p, err := st.path(id)
+	if err != nil {
+		return nil, nil
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fsess fileSession
+	if err := json.Unmarshal(b, &fsess); err != nil || time.Now().After(fsess.Expires) {
+		os.Remove(p)
+		return nil, nil
+	}
+	return fsess.Data, nil
+}
+
+func (st *FileSessionStore) Save(ctx context.Context, id string, data []byte, expires time.Time) error {
+	p, err := st.path(id)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(fileSession{Expires: expires, Data: data})
+	if err != nil {
+		return err
+	}
+
+	// 임시파일에 쓰고 rename (동시 요청이 반쯤 쓴 파일을 읽지 않게)
+	f, err := os.CreateTemp(st.Dir, id+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), p); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	st.sweep()
+	return nil
+}
+
+func (st *FileSessionStore) Delete(ctx context.Context, id string) error {
+	p, err := st.path(id)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (st *FileSessionStore) sweep() {
+	st.mu.Lock()
+	now := time.Now()
+	if now.Sub(st.swept) <= sessionSweepInterval {
+		st.mu.Unlock()
+		return
+	}
+	st.swept = now
+	st.mu.Unlock()
+
+	entries, err := os.ReadDir(st.Dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || !sessionIDPattern.MatchString(id) {
+			continue
+		}
+		st.Load(context.Background(), id) // 만료된 파일은 Load 가 지움
+	}
+}
+
+/*
+SQL 세션 저장소 (App.Conns 의 커넥션 사용, MySQL)
+
+	CREATE TABLE sessions (
+	    id      CHAR(64)   NOT NULL PRIMARY KEY,
+	    data    MEDIUMBLOB NOT NULL,
+	    expires BIGINT     NOT NULL,
+	    INDEX (expires)
+	)
+*/
+type SQLSessionStore struct {
+	DB    *sql.DB
+	Table string
+
+	mu    sync.Mutex
+	swept time.Time
+}
+
+// AddConn 으로 등록한 커넥션 키 사용
+func (a *App) NewSQLSessionStore(key, table string) *SQLSessionStore {
+	db := a.GetConn(key)
+	if db == nil {
+		panic(fmt.Errorf("connection %q not registered", key))
+	}
+	return &SQLSessionStore{DB: db, Table: table}
+}
+
+func (st *SQLSessionStore) Load(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	err := st.DB.QueryRowContext(ctx, fmt.Sprintf(
+		"SELECT data FROM %s WHERE id = ? AND expires > ?", st.Table,
+	), id, time.Now().Unix()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (st *SQLSessionStore) Save(ctx context.Context, id string, data []byte, expires time.Time) error {
+	_, err := st.DB.ExecContext(ctx, fmt.Sprintf(
+		"INSERT INTO %s (id, data, expires) VALUES (?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE data = VALUES(data), expires = VALUES(expires)", st.Table,
+	), id, data, expires.Unix())
+	if err != nil {
+		return err
+	}
+
+	st.mu.Lock()
+	sweep := time.Since(st.swept) > sessionSweepInterval
+	if sweep {
+		st.swept = time.Now()
+	}
+	st.mu.Unlock()
+	if sweep {
+		_, err = st.DB.ExecContext(ctx, fmt.Sprintf(
+			"DELETE FROM %s WHERE expires <= ?", st.Table,
+		), time.Now().Unix())
+	}
+	return err
+}
+
+func (st *SQLSessionStore) Delete(ctx context.Context, id string) error {
+	_, err := st.DB.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = ?", st.Table), id)
+	return err
+}
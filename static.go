@@ -0,0 +1,276 @@
+package x
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 정적 파일 캐시 규칙
+type CacheRule struct {
+	// ".css" 확장자, "/assets/" 경로 접두어, 그 외는 path.Match 패턴 ("/img/*.png")
+	Match        string
+	CacheControl string
+}
+
+/*
+정적 파일 서빙 정책
+  - Cache-Control: 지문 파일명은 immutable, 그 외 CacheRules 에서 먼저 맞는 규칙, 없으면 DefaultCache
+  - ETag: 파일 내용 sha256 (강한 검증자), 수정시각/크기가 바뀌면 다시 계산
+  - Precompressed: Accept-Encoding 이 허용하면 미리 만들어 둔 .br/.gz 파일을 대신 서빙
+*/
+type Static struct {
+	CacheRules    []CacheRule
+	DefaultCache  string
+	Fingerprint   *regexp.Regexp // 매칭되는 파일명은 1년 immutable 캐시 (첫 그룹이 해시)
+	Precompressed bool
+
+	// 숨김/백업 파일 차단 (404 로 응답해 존재 여부도 숨김)
+	DenyDotfiles bool     // 경로 중 . 으로 시작하는 부분이 있으면 차단 (.env, .git/...)
+	AllowPaths   []string // DenyDotfiles 예외 경로 접두어 (/.well-known/)
+	DenyPatterns []string // 파일/디렉토리 이름에 대한 path.Match 패턴
+
+	etags sync.Map // 경로 → staticETag
+}
+
+type staticETag struct {
+	modTime time.Time
+	size    int64
+	etag    string
+}
+
+// 빌드 도구가 붙인 내용 해시 (app.3f2a9c1e.js, main-0123456789abcdef.css)
+var defaultFingerprint = regexp.MustCompile(`[.-]([0-9a-f]{8,})\.[A-Za-z0-9]+$`)
+
+func NewStatic() *Static {
+	return &Static{
+		DefaultCache:  "no-cache",
+		Fingerprint:   defaultFingerprint,
+		Precompressed: true,
+		DenyDotfiles:  true,
+		AllowPaths:    []string{"/.well-known/"},
+		DenyPatterns:  []string{"*~", "*.bak", "*.swp", "*.old", "*.orig"},
+	}
+}
+
+// 캐시 규칙 추가
+func (s *Static) Cache(match, cacheControl string) *Static {
+	s.CacheRules = append(s.CacheRules, CacheRule{Match: match, CacheControl: cacheControl})
+	return s
+}
+
+func (s *Static) cacheControl(name string) string {
+	if s.fingerprinted(name) {
+		return "public, max-age=31536000, immutable"
+	}
+	for _, rule := range s.CacheRules {
+		switch {
+		case strings.HasPrefix(rule.Match, "."):
+			if strings.EqualFold(path.Ext(name), rule.Match) {
+				return rule.CacheControl
+			}
+		case strings.HasSuffix(rule.Match, "/"):
+			if strings.HasPrefix(name, rule.Match) {
+				return rule.CacheControl
+			}
+		default:
+			if ok, _ := path.Match(rule.Match, name); ok {
+				return rule.CacheControl
+			}
+		}
+	}
+	return s.DefaultCache
+}
+
+// 해시가 숫자만이면 지문이 아님 (banner-20241019.png 같은 날짜/번호는 제자리 수정되면 1년간 갱신 안 됨)
+func (s *Static) fingerprinted(name string) bool {
+	if s.Fingerprint == nil {
+		return false
+	}
+	m := s.Fingerprint.FindStringSubmatch(path.Base(name))
+	if m == nil {
+		return false
+	}
+	return len(m) < 2 || strings.ContainsAny(m[1], "abcdef")
+}
+
+// index.html 이 없는 디렉토리 (목록을 보여주지 않음)
+var ErrDirListing = errors.New("directory listing denied")
+
+// 숨김/백업 파일 등 정책상 서빙하지 않는 경로
+var ErrDenied = errors.New("static path denied")
+
+/*
+name 이 서빙 금지 경로인지
+  - 정리 후에도 .. / 역슬래시 / NUL 이 남은 경로 (인코딩된 traversal 시도)
+  - DenyDotfiles: . 으로 시작하는 경로 요소 (AllowPaths 접두어에 해당하는 요소만 예외)
+  - DenyPatterns: 경로 요소 이름이 패턴과 일치
+
+디스크 WebRoot 는 os.Root 로 열어 심볼릭 링크로 밖을 가리키는 파일도 열리지 않음
+*/
+func (s *Static) Denied(name string) bool {
+	if strings.ContainsAny(name, "\\\x00") {
+		return true
+	}
+	rel := strings.TrimPrefix(name, "/")
+	if rel == "" {
+		return false
+	}
+	if !fs.ValidPath(rel) {
+		return true
+	}
+
+	// AllowPaths 접두어 부분만 예외, 그 아래 경로 요소는 다시 검사 (/.well-known/.git/config 차단)
+	skip := 0
+	for _, p := range s.AllowPaths {
+		if t := strings.Trim(p, "/"); t != "" && strings.HasPrefix(name+"/", p) {
+			skip = max(skip, strings.Count(t, "/")+1)
+		}
+	}
+	for i, part := range strings.Split(rel, "/") {
+		if s.DenyDotfiles && i >= skip && strings.HasPrefix(part, ".") {
+			return true
+		}
+		for _, pattern := range s.DenyPatterns {
+			if ok, _ := path.Match(pattern, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+fsys 의 name 파일 서빙 (name 은 "/" 로 시작하는 정리된 URL 경로)
+디렉토리면 index.html 을 서빙. 응답하지 못한 경우 에러 반환 (호출측에서 처리)
+  - fs.ErrNotExist: 파일 없음
+  - ErrDirListing: index.html 없는 디렉토리
+  - ErrDenied: 숨김/백업 파일 등 차단된 경로
+*/
+func (s *Static) Serve(c *Context, fsys fs.FS, name string) error {
+	if s.Denied(name) {
+		return ErrDenied
+	}
+	rel := strings.TrimPrefix(name, "/")
+	if rel == "" {
+		rel = "."
+	}
+
+	info, err := fs.Stat(fsys, rel)
+	if err != nil {
+		return fs.ErrNotExist
+	}
+	if info.IsDir() {
+		// 디렉토리는 / 로 끝나는 URL 로 맞춤 (상대경로 리소스 기준)
+		if !strings.HasSuffix(c.Req.URL.Path, "/") {
+			target := path.Base(c.Req.URL.Path) + "/"
+			if q := c.Req.URL.RawQuery; q != "" {
+				target += "?" + q
+			}
+			http.Redirect(c.Res, c.Req, target, http.StatusMovedPermanently)
+			return nil
+		}
+		rel = path.Join(rel, "index.html")
+		if info, err = fs.Stat(fsys, rel); err != nil || info.IsDir() {
+			return ErrDirListing
+		}
+	}
+
+	h := c.Res.Header()
+	if ct := mime.TypeByExtension(path.Ext(rel)); ct != "" {
+		h.Set("Content-Type", ct)
+	}
+	h.Set("Cache-Control", s.cacheControl("/"+rel))
+
+	// 미리 압축된 파일
+	if s.Precompressed {
+		served := rel
+		for _, enc := range []struct{ name, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+			sibling, err := fs.Stat(fsys, rel+enc.ext)
+			if err != nil || sibling.IsDir() {
+				continue
+			}
+			h.Add("Vary", "Accept-Encoding")
+			if served == rel && acceptsEncoding(c.Req.Header.Get("Accept-Encoding"), enc.name) {
+				h.Set("Content-Encoding", enc.name)
+				served, info = rel+enc.ext, sibling
+			}
+		}
+		rel = served
+	}
+
+	f, err := fsys.Open(rel)
+	if err != nil {
+		return fs.ErrNotExist
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(b)
+	}
+
+	if etag, err := s.etag(rel, info, content); err == nil {
+		h.Set("ETag", etag)
+	}
+	http.ServeContent(c.Res, c.Req, rel, info.ModTime(), content)
+	return nil
+}
+
+// 내용 해시 ETag. 수정시각과 크기가 같으면 캐시 사용
+func (s *Static) etag(rel string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if v, ok := s.etags.Load(rel); ok {
+		e := v.(staticETag)
+		if e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+			return e.etag, nil
+		}
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	s.etags.Store(rel, staticETag{modTime: info.ModTime(), size: info.Size(), etag: etag})
+	return etag, nil
+}
+
+// Accept-Encoding 에 enc 가 q>0 으로 포함되는지 (명시한 항목이 * 보다 우선)
+func acceptsEncoding(header, enc string) bool {
+	star := false
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.TrimSpace(name)
+		ok := true
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if f, err := strconv.ParseFloat(q, 64); err == nil && f == 0 {
+				ok = false
+			}
+		}
+		if strings.EqualFold(name, enc) {
+			return ok
+		}
+		if name == "*" {
+			star = ok
+		}
+	}
+	return star
+}
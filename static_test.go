@@ -0,0 +1,173 @@
+package x
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const outsideSecret = "TOP-SECRET"
+
+// tmp/secret.txt 는 WebRoot(tmp/www) 밖의 파일
+func newStaticTestApp(t *testing.T) *App {
+	t.Helper()
+	tmp := t.TempDir()
+	root := filepath.Join(tmp, "www")
+
+	files := map[string]string{
+		"secret.txt":                         outsideSecret,
+		"www/index.html":                     "<h1>index</h1>",
+		"www/real.txt":                       "real",
+		"www/.env":                           "DB_PASSWORD=x",
+		"www/.git/config":                    "[core]",
+		"www/backup.bak":                     "old",
+		"www/notes.txt~":                     "old",
+		"www/.well-known/security.txt":       "Contact: mailto:security@example.com",
+		"www/.well-known/.git/config":        "[core]",
+		"www/sub/.hidden/file.txt":           "hidden",
+		"www/assets/app.3f2a9c1e.js":         "app",
+		"www/assets/banner-20241019.js":      "banner",
+		"www/assets/log.2024101912345678.js": "log",
+	}
+	for name, body := range files {
+		p := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(tmp, "secret.txt"), filepath.Join(root, "escape.txt")); err != nil {
+		t.Skip("symlink not supported:", err)
+	}
+	if err := os.Symlink(filepath.Join(tmp, ".."), filepath.Join(root, "updir")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("real.txt", filepath.Join(root, "inside.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewApp(root)
+	a.SetLogger(slog.LevelError+1, "", "") // 테스트 출력에 로그 남기지 않음
+	return a
+}
+
+func serveStatic(a *App, target string) *httptest.ResponseRecorder {
+	// 클라이언트 정규화를 거치지 않은 원본 요청 경로 그대로
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	a.Server.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStaticTraversal(t *testing.T) {
+	a := newStaticTestApp(t)
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"encoded dot segments", "/%2e%2e/secret.txt", http.StatusNotFound},
+		{"double encoded dot segments", "/%2e%2e/%2e%2e/secret.txt", http.StatusNotFound},
+		{"encoded slash", "/..%2fsecret.txt", http.StatusNotFound},
+		{"encoded slash twice", "/..%2f..%2fsecret.txt", http.StatusNotFound},
+		{"mixed encoding", "/.%2e/secret.txt", http.StatusNotFound},
+		{"backslash", `/..\secret.txt`, http.StatusNotFound},
+		{"encoded backslash", "/..%5csecret.txt", http.StatusNotFound},
+		{"nul byte", "/real.txt%00.html", http.StatusNotFound},
+		{"nul byte in dir", "/%00/../secret.txt", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveStatic(a, tt.target)
+			if rec.Code != tt.status {
+				t.Errorf("GET %s = %d, want %d", tt.target, rec.Code, tt.status)
+			}
+			if strings.Contains(rec.Body.String(), outsideSecret) {
+				t.Errorf("GET %s leaked file outside WebRoot", tt.target)
+			}
+		})
+	}
+}
+
+func TestStaticDenied(t *testing.T) {
+	a := newStaticTestApp(t)
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"dotfile", "/.env", http.StatusNotFound},
+		{"dot directory", "/.git/config", http.StatusNotFound},
+		{"nested dot directory", "/sub/.hidden/file.txt", http.StatusNotFound},
+		{"encoded dotfile", "/%2eenv", http.StatusNotFound},
+		{"bak backup", "/backup.bak", http.StatusNotFound},
+		{"tilde backup", "/notes.txt~", http.StatusNotFound},
+		{"well-known exception", "/.well-known/security.txt", http.StatusOK},
+		{"dotfile under well-known", "/.well-known/.git/config", http.StatusNotFound},
+		{"regular file", "/real.txt", http.StatusOK},
+		{"index", "/", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serveStatic(a, tt.target); rec.Code != tt.status {
+				t.Errorf("GET %s = %d, want %d", tt.target, rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestStaticSymlinks(t *testing.T) {
+	a := newStaticTestApp(t)
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+		body   string
+	}{
+		{"file outside WebRoot", "/escape.txt", http.StatusNotFound, ""},
+		{"directory outside WebRoot", "/updir/secret.txt", http.StatusNotFound, ""},
+		{"file inside WebRoot", "/inside.txt", http.StatusOK, "real"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveStatic(a, tt.target)
+			if rec.Code != tt.status {
+				t.Errorf("GET %s = %d, want %d", tt.target, rec.Code, tt.status)
+			}
+			if strings.Contains(rec.Body.String(), outsideSecret) {
+				t.Errorf("GET %s leaked file outside WebRoot", tt.target)
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("GET %s body = %q, want %q", tt.target, rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestStaticFingerprintCache(t *testing.T) {
+	a := newStaticTestApp(t)
+
+	tests := []struct {
+		target string
+		cache  string
+	}{
+		{"/assets/app.3f2a9c1e.js", "public, max-age=31536000, immutable"},
+		{"/assets/banner-20241019.js", "no-cache"},
+		{"/assets/log.2024101912345678.js", "no-cache"},
+	}
+	for _, tt := range tests {
+		rec := serveStatic(a, tt.target)
+		if got := rec.Header().Get("Cache-Control"); got != tt.cache {
+			t.Errorf("GET %s Cache-Control = %q, want %q", tt.target, got, tt.cache)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package x
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+/*
+html/template 묶음. Dir 아래 구조 예시
+
+	layouts/base.html    {{define "base"}} ... {{template "content" .}} ... {{end}}
+	partials/nav.html    {{define "nav"}} ... {{end}}
+	address/list.html    {{template "base" .}}{{define "content"}} ... {{end}}
+
+Shared 디렉토리(layouts, partials)의 파일은 모든 페이지에서 공유되고
+나머지 파일은 각각 하나의 페이지가 됨. 페이지 이름은 확장자를 뺀 상대경로 (address/list)
+*/
+type Templates struct {
+	FS        fs.FS
+	Dir       string
+	Ext       string
+	Shared    []string
+	Funcs     template.FuncMap
+	ErrorPage string // 에러 응답에 쓸 페이지 이름 (비어있으면 기본 문구)
+	Dev       bool   // true 이면 렌더할 때마다 다시 파싱 (개발용 hot reload)
+
+	mu    sync.RWMutex
+	pages map[string]*page
+}
+
+/*
+요청마다 값이 달라지는 템플릿 함수 (cspNonce 등)
+파싱 때는 빈 문자열을 돌려주는 함수로 등록되고, 렌더용 사본에서는 렌더 중인 요청의 값을 돌려줌
+*/
+var requestFuncs = map[string]func(c *Context) string{}
+
+// 페이지 원본과 렌더용 사본 풀
+// 원본은 실행하면 Clone 할 수 없으므로 실행하지 않고, 사본은 한 번 이스케이프된 채로 재사용
+type page struct {
+	tmpl *template.Template
+	pool sync.Pool // *boundPage
+}
+
+// 요청 함수가 c 를 읽도록 묶인 사본 (풀에서 꺼낸 동안 한 렌더만 사용)
+type boundPage struct {
+	tmpl *template.Template
+	c    *Context
+}
+
+func (p *page) get() (*boundPage, error) {
+	if b, ok := p.pool.Get().(*boundPage); ok {
+		return b, nil
+	}
+	tmpl, err := p.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	b := &boundPage{}
+	funcs := template.FuncMap{}
+	for name, fn := range requestFuncs {
+		funcs[name] = func() string {
+			if b.c == nil {
+				return ""
+			}
+			return fn(b.c)
+		}
+	}
+	b.tmpl = tmpl.Funcs(funcs)
+	return b, nil
+}
+
+func placeholderFuncs() template.FuncMap {
+	funcs := template.FuncMap{}
+	for name := range requestFuncs {
+		funcs[name] = func() string { return "" }
+	}
+	return funcs
+}
+
+func NewTemplates(fsys fs.FS, dir string) *Templates {
+	return &Templates{
+		FS:     fsys,
+		Dir:    dir,
+		Ext:    ".html",
+		Shared: []string{"layouts", "partials"},
+		Funcs:  template.FuncMap{},
+	}
+}
+
+// 템플릿 전체 파싱
+func (t *Templates) Load() error {
+	var shared, pages []string
+	err := fs.WalkDir(t.FS, t.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, t.Ext) {
+			return nil
+		}
+		if t.isShared(p) {
+			shared = append(shared, p)
+		} else {
+			pages = append(pages, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	base := template.New("").Funcs(placeholderFuncs()).Funcs(t.Funcs)
+	if len(shared) > 0 {
+		if base, err = base.ParseFS(t.FS, shared...); err != nil {
+			return err
+		}
+	}
+
+	loaded := make(map[string]*page, len(pages))
+	for _, p := range pages {
+		tmpl, err := base.Clone()
+		if err != nil {
+			return err
+		}
+		if tmpl, err = tmpl.ParseFS(t.FS, p); err != nil {
+			return err
+		}
+		loaded[t.pageName(p)] = &page{tmpl: tmpl.Lookup(path.Base(p))}
+	}
+
+	t.mu.Lock()
+	t.pages = loaded
+	t.mu.Unlock()
+	return nil
+}
+
+func (t *Templates) rel(p string) string {
+	dir := path.Clean(t.Dir)
+	if dir == "." {
+		return p
+	}
+	return strings.TrimPrefix(p, dir+"/")
+}
+
+func (t *Templates) isShared(p string) bool {
+	for _, dir := range t.Shared {
+		if strings.HasPrefix(t.rel(p), dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Templates) pageName(p string) string {
+	return strings.TrimSuffix(t.rel(p), t.Ext)
+}
+
+// 페이지 렌더링. 실행이 끝난 뒤에 쓰므로 실패 시 부분 응답이 나가지 않음
+func (t *Templates) Render(w io.Writer, name string, data any) error {
+	return t.render(w, name, data, nil)
+}
+
+// c 는 요청 함수(cspNonce 등)가 값을 읽을 요청 (nil 이면 빈 문자열)
+func (t *Templates) render(w io.Writer, name string, data any, c *Context) error {
+	if t.Dev {
+		if err := t.Load(); err != nil {
+			return err
+		}
+	}
+
+	t.mu.RLock()
+	p, ok := t.pages[name]
+	t.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("template %q not found", name)
+	}
+
+	b, err := p.get()
+	if err != nil {
+		return err
+	}
+	b.c = c
+	var buf bytes.Buffer
+	err = b.tmpl.Execute(&buf, data)
+	b.c = nil
+	p.pool.Put(b)
+	if err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// 템플릿 등록 (fsys 는 os.DirFS 또는 embed.FS). 파싱 실패 시 패닉
+// Funcs 가 필요하면 NewTemplates 후 Funcs 지정, Load 해서 a.Templates 에 직접 할당
+func (a *App) SetTemplates(fsys fs.FS, dir string, dev bool) *Templates {
+	t := NewTemplates(fsys, dir)
+	t.Dev = dev
+	if err := t.Load(); err != nil {
+		panic(err)
+	}
+	a.Templates = t
+	a.Logger.Info(PrependX("Templates loaded"), "Dir", dir, "Pages", len(t.pages), "Dev", dev)
+	return t
+}
+
+// ReplyHTML 로 렌더할 템플릿과 데이터 지정
+func (c *Context) Render(name string, data any) {
+	c.Template = name
+	c.Response.Data = data
+}
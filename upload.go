@@ -0,0 +1,212 @@
+package x
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/simjinhyun/x/util"
+)
+
+// 라우트별 업로드 제한
+type UploadLimits struct {
+	MaxFiles    int      // 요청당 최대 파일 수 (0이면 무제한)
+	MaxFileSize int64    // 파일당 최대 크기 (0이면 무제한)
+	AllowedMIME []string // 내용으로 판별한 MIME 허용 목록 ("image/*" 가능, 비어있으면 모두 허용)
+}
+
+// 업로드 제한 설정
+func (rt *Route) WithUpload(l UploadLimits) *Route {
+	rt.Upload = l
+	return rt
+}
+
+// 멀티파트 파싱 후 라우트 업로드 제한 검사 (요청당 1회)
+func (c *Context) multipartForm() *multipart.Form {
+	if c.uploadChecked {
+		return c.Req.MultipartForm
+	}
+	if err := c.Req.ParseMultipartForm(multipartMemory); err != nil {
+		if errors.Is(err, http.ErrNotMultipart) {
+			NewAppError("NotMultipart", err, nil).WithStatus(http.StatusBadRequest).Panic()
+		}
+		bodyError(err).Panic()
+	}
+
+	var limits UploadLimits
+	if c.Route != nil {
+		limits = c.Route.Upload
+	}
+
+	count := 0
+	for field, files := range c.Req.MultipartForm.File {
+		for _, fh := range files {
+			count++
+			if limits.MaxFiles > 0 && count > limits.MaxFiles {
+				NewAppError("TooManyFiles", nil, map[string]any{"Max": limits.MaxFiles}).
+					WithStatus(http.StatusRequestEntityTooLarge).Panic()
+			}
+			if limits.MaxFileSize > 0 && fh.Size > limits.MaxFileSize {
+				NewAppError("FileTooLarge", nil, map[string]any{"Field": field, "Max": limits.MaxFileSize}).
+					WithStatus(http.StatusRequestEntityTooLarge).Panic()
+			}
+			if len(limits.AllowedMIME) > 0 {
+				mt := SniffMIME(fh)
+				if !matchMIME(limits.AllowedMIME, mt) {
+					NewAppError("FileTypeNotAllowed", nil, map[string]any{"Field": field, "MIME": mt}).
+						WithStatus(http.StatusUnsupportedMediaType).Panic()
+				}
+			}
+		}
+	}
+	c.uploadChecked = true
+	return c.Req.MultipartForm
+}
+
+// 업로드 파일 1개. 없으면 FileRequired
+func (c *Context) FormFile(name string) *multipart.FileHeader {
+	files := c.FormFiles(name)
+	if len(files) == 0 {
+		NewAppError("FileRequired", nil, map[string]any{"Field": name}).
+			WithStatus(http.StatusBadRequest).Panic()
+	}
+	return files[0]
+}
+
+// 같은 이름으로 올라온 업로드 파일 목록
+func (c *Context) FormFiles(name string) []*multipart.FileHeader {
+	return c.multipartForm().File[name]
+}
+
+// 파일 앞부분으로 판별한 MIME (클라이언트가 보낸 Content-Type 은 믿지 않음)
+func SniffMIME(fh *multipart.FileHeader) string {
+	f, err := fh.Open()
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, _ := io.ReadFull(f, buf)
+	mt, _, _ := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	return mt
+}
+
+func matchMIME(allowed []string, mt string) bool {
+	for _, a := range allowed {
+		if a == mt {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(mt, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+var safeExt = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+/*
+저장용 파일명: 추측 불가능한 UUID + 확장자 (원본 파일명은 쓰지 않음)
+확장자는 내용으로 판별한 MIME 기준. 클라이언트 확장자는 같은 MIME 을 가리킬 때만 사용
+(PNG 내용에 evil.html 이름을 붙여 .html 로 저장되는 것 방지)
+*/
+func SafeFileName(fh *multipart.FileHeader) string {
+	return util.UUIDFromCryptoPackage() + safeExtension(fh)
+}
+
+func safeExtension(fh *multipart.FileHeader) string {
+	sniffed := SniffMIME(fh)
+	ext := strings.ToLower(path.Ext(fh.Filename))
+	if mt, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext)); !safeExt.MatchString(ext) || mt != sniffed {
+		ext = ""
+		if exts, _ := mime.ExtensionsByType(sniffed); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	return ext
+}
+
+// WebRoot 에 저장해도 되는 형식 (브라우저가 스크립트로 실행하지 않는 것만)
+// 내용으로 판별한 MIME 과 허용 확장자, 첫 번째가 기본값
+var passiveTypes = map[string][]string{
+	"image/png":       {".png"},
+	"image/jpeg":      {".jpg", ".jpeg"},
+	"image/gif":       {".gif"},
+	"image/webp":      {".webp"},
+	"image/bmp":       {".bmp"},
+	"image/x-icon":    {".ico"},
+	"application/pdf": {".pdf"},
+	"text/plain":      {".txt"},
+}
+
+// 업로드 파일을 dir 에 안전한 이름으로 저장하고 저장된 파일명 반환
+func (c *Context) SaveUploadedFile(fh *multipart.FileHeader, dir string) string {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		NewAppError("UploadFailed", err, nil).Panic()
+	}
+	return c.saveFile(fh, dir, SafeFileName(fh))
+}
+
+func (c *Context) saveFile(fh *multipart.FileHeader, dir, name string) string {
+	src, err := fh.Open()
+	if err != nil {
+		NewAppError("UploadFailed", err, nil).Panic()
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		NewAppError("UploadFailed", err, nil).Panic()
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		NewAppError("UploadFailed", err, nil).Panic()
+	}
+	c.Debug("Upload saved", "File", fh.Filename, "Size", fh.Size, "Name", name)
+	return name
+}
+
+/*
+WebRoot 하위 디렉토리에 저장하고 URL 경로 반환 (예: /uploads/xxxx.png)
+디렉토리 목록은 라우터가 거부하므로 파일명(UUID)을 모르면 접근 불가
+이미지/pdf/텍스트(passiveTypes) 외의 형식은 FileTypeNotAllowed (415)
+(html/svg/xml 등 브라우저가 스크립트를 실행할 수 있는 형식이 섞이지 않게 허용 목록으로)
+*/
+func (c *Context) SaveToWebRoot(fh *multipart.FileHeader, subdir string) string {
+	if c.App.Router.WebRoot == "" {
+		NewAppError("UploadFailed", fmt.Errorf("no disk WebRoot to store uploads"), nil).Panic()
+	}
+	rel := path.Clean("/" + subdir)
+	if rel == "/" {
+		NewAppError("UploadFailed", fmt.Errorf("upload subdir required"), nil).Panic()
+	}
+	mt := SniffMIME(fh)
+	exts, ok := passiveTypes[mt]
+	if !ok {
+		NewAppError("FileTypeNotAllowed", nil, map[string]any{"File": fh.Filename, "MIME": mt}).
+			WithStatus(http.StatusUnsupportedMediaType).Panic()
+	}
+	// 확장자도 허용 목록에서만 (클라이언트 확장자는 같은 형식일 때만)
+	ext := exts[0]
+	if e := strings.ToLower(path.Ext(fh.Filename)); slices.Contains(exts, e) {
+		ext = e
+	}
+
+	dir := filepath.Join(c.App.Router.WebRoot, filepath.FromSlash(rel))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		NewAppError("UploadFailed", err, nil).Panic()
+	}
+	name := c.saveFile(fh, dir, util.UUIDFromCryptoPackage()+ext)
+	return path.Join(rel, name)
+}